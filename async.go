@@ -0,0 +1,91 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"time"
+)
+
+// Future 异步请求的结果
+type Future struct {
+	done chan struct{}
+	resp easyCon.PackResp
+}
+
+// Done 请求完成时关闭的通道，可用于 select
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait 等待请求完成并返回响应
+func (f *Future) Wait() easyCon.PackResp {
+	<-f.done
+	return f.resp
+}
+
+// BatchReq SendRequestAll 中的单个请求
+type BatchReq struct {
+	Module string // 目标模块
+	Route  string // 路由
+	Params []byte // 请求内容
+}
+
+// SendRequestAsync 异步发送请求，立即返回，通过 Future 获取响应
+// timeout 为0时使用 Broker.TimeOut
+func (bll *Service) SendRequestAsync(module, route string, params []byte, timeout int, opts ...CallOption) *Future {
+	f := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		f.resp = bll.sendOut(&Outbound{Kind: EOutKindRequest, Module: module, Route: route, Content: params, Timeout: timeout}, opts...)
+	}()
+	return f
+}
+
+// SendRequestAll 并发发送多个请求，按请求顺序返回响应
+// 所有请求共用一个截止时间（毫秒，0时使用 Broker.TimeOut），截止时仍未完成的请求返回 ERespTimeout 并不再重试
+func (bll *Service) SendRequestAll(reqs []BatchReq, timeout int, opts ...CallOption) []easyCon.PackResp {
+	if timeout <= 0 {
+		timeout = bll.cfg.getBase().Broker.TimeOut
+	}
+	// 截止或返回后停止重试，选项中设置了 ctx 时同时受其控制
+	deadline, cancel := goContext.WithTimeout(goContext.Background(), time.Duration(timeout)*time.Millisecond)
+	defer cancel()
+	opts = append(opts[:len(opts):len(opts)], func(out *Outbound) {
+		if out.Ctx == nil {
+			out.Ctx = deadline
+			return
+		}
+		ctx, stop := goContext.WithCancel(out.Ctx)
+		goContext.AfterFunc(deadline, stop)
+		out.Ctx = ctx
+	})
+	futures := make([]*Future, len(reqs))
+	for i, req := range reqs {
+		futures[i] = bll.SendRequestAsync(req.Module, req.Route, req.Params, timeout, opts...)
+	}
+
+	expired := false
+	resps := make([]easyCon.PackResp, len(reqs))
+	for i, f := range futures {
+		if !expired {
+			select {
+			case <-f.Done():
+			case <-deadline.Done():
+				expired = true
+			}
+		}
+		if expired {
+			// 截止后只收集已完成的请求
+			select {
+			case <-f.Done():
+			default:
+				content := fmt.Sprintf("request to %s.%s not finished in %dms", reqs[i].Module, reqs[i].Route, timeout)
+				resps[i] = easyCon.PackResp{RespCode: easyCon.ERespTimeout, PackReq: easyCon.PackReq{To: reqs[i].Module, Route: reqs[i].Route, Content: []byte(content)}}
+				continue
+			}
+		}
+		resps[i] = f.resp
+	}
+	return resps
+}
@@ -0,0 +1,148 @@
+package qf
+
+import (
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ERespBreakerOpen 熔断器打开时直接返回的响应码，请求未实际发出
+const ERespBreakerOpen easyCon.EResp = 503
+
+// EBreakerState 熔断器状态
+type EBreakerState string
+
+const (
+	EBreakerClosed   EBreakerState = "Closed"   // 正常放行
+	EBreakerOpen     EBreakerState = "Open"     // 熔断中，请求直接失败
+	EBreakerHalfOpen EBreakerState = "HalfOpen" // 半开，放行一个探测请求
+)
+
+// BreakerState 熔断器状态信息，通过内置路由 Breakers 获取
+type BreakerState struct {
+	Module   string        // 目标模块
+	Route    string        // 目标路由
+	State    EBreakerState // 当前状态
+	Failures int           // 连续失败次数
+	OpenedAt string        `json:",omitempty"` // 最近一次打开的时间
+}
+
+// breaker 单个目标路由的熔断器
+type breaker struct {
+	module   string
+	route    string
+	state    EBreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // 半开状态下是否已有探测请求
+}
+
+// breakerSet 按 模块名.路由名 保存的熔断器
+type breakerSet struct {
+	lock  sync.Mutex
+	items map[string]*breaker
+}
+
+// allow 是否放行请求，熔断时间到后转为半开并放行一个探测请求
+func (b *breaker) allow(openTime time.Duration) bool {
+	switch b.state {
+	case EBreakerOpen:
+		if time.Since(b.openedAt) < openTime {
+			return false
+		}
+		b.state = EBreakerHalfOpen
+		b.probing = true
+		return true
+	case EBreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// record 记录请求结果，返回熔断器是否因此打开
+// 只有超时视为目标不可用，其他响应码说明目标仍在工作
+// 未连接是本模块与Broker的连接断开，与目标无关，不改变熔断状态
+func (b *breaker) record(code easyCon.EResp, threshold int) bool {
+	b.probing = false
+	if code == easyCon.ERespUnLinked {
+		return false
+	}
+	if code != easyCon.ERespTimeout {
+		b.state = EBreakerClosed
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.state == EBreakerHalfOpen || (b.state == EBreakerClosed && b.failures >= threshold) {
+		b.state = EBreakerOpen
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// breaker 熔断拦截器，位于拦截器链的最内层，Base.Request.BreakerThreshold 小于等于0时不启用
+func (bll *Service) breaker(next Sender) Sender {
+	return func(out *Outbound) easyCon.PackResp {
+		cfg := bll.cfg.getBase().Request
+		if out.Kind != EOutKindRequest || cfg.BreakerThreshold <= 0 {
+			return next(out)
+		}
+		key := out.Module + "." + out.Route
+		openTime := time.Duration(cfg.BreakerOpenTime) * time.Millisecond
+
+		bll.breakers.lock.Lock()
+		if bll.breakers.items == nil {
+			bll.breakers.items = map[string]*breaker{}
+		}
+		b, ok := bll.breakers.items[key]
+		if !ok {
+			b = &breaker{module: out.Module, route: out.Route, state: EBreakerClosed}
+			bll.breakers.items[key] = b
+		}
+		allow := b.allow(openTime)
+		bll.breakers.lock.Unlock()
+		if !allow {
+			return easyCon.PackResp{RespCode: ERespBreakerOpen, PackReq: easyCon.PackReq{Content: []byte(fmt.Sprintf("circuit breaker of %s is open", key))}}
+		}
+
+		resp := next(out)
+
+		bll.breakers.lock.Lock()
+		opened := b.record(resp.RespCode, cfg.BreakerThreshold)
+		failures := b.failures
+		bll.breakers.lock.Unlock()
+		if opened {
+			bll.Logger().Warn("circuit breaker open", "target", key, "failures", failures, "openTime", openTime)
+		}
+		return resp
+	}
+}
+
+// breakerStates 获取所有熔断器的状态，按模块和路由排序
+func (bll *Service) breakerStates() []BreakerState {
+	bll.breakers.lock.Lock()
+	defer bll.breakers.lock.Unlock()
+
+	list := make([]BreakerState, 0, len(bll.breakers.items))
+	for _, b := range bll.breakers.items {
+		s := BreakerState{Module: b.module, Route: b.route, State: b.state, Failures: b.failures}
+		if !b.openedAt.IsZero() {
+			s.OpenedAt = b.openedAt.Format("2006-01-02 15:04:05.000")
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Module != list[j].Module {
+			return list[i].Module < list[j].Module
+		}
+		return list[i].Route < list[j].Route
+	})
+	return list
+}
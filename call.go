@@ -0,0 +1,64 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"reflect"
+)
+
+// Call 发送请求并将响应解析为 Resp 类型
+// req 的编码方式与 Invoke 的解析方式对应：string 和 []byte 原样发送，其他类型使用 Base.Request.Codec 配置的编解码
+// 响应码不为成功时返回 *Error，被调用方返回的结构化错误会完整还原
+func Call[Resp any](s *Service, module, route string, req any, opts ...CallOption) (Resp, error) {
+	return call[Resp](s, module, route, req, 0, opts)
+}
+
+// CallWithTimeout 发送请求并将响应解析为 Resp 类型(可自定义超时时间的,单位毫秒)
+func CallWithTimeout[Resp any](s *Service, module, route string, req any, timeout int, opts ...CallOption) (Resp, error) {
+	return call[Resp](s, module, route, req, timeout, opts)
+}
+
+// CallContext 同 Call，沿用 ctx 所在请求的链路Id，ctx 结束后不再重试，处理请求时调用其他模块应使用此方法，ctx 一般为业务方法的 IContext
+func CallContext[Resp any](ctx goContext.Context, s *Service, module, route string, req any, opts ...CallOption) (Resp, error) {
+	return call[Resp](s, module, route, req, 0, append([]CallOption{WithContext(ctx)}, opts...))
+}
+
+// CallContextWithTimeout 同 CallWithTimeout，沿用 ctx 所在请求的链路Id，ctx 结束后不再重试
+func CallContextWithTimeout[Resp any](ctx goContext.Context, s *Service, module, route string, req any, timeout int, opts ...CallOption) (Resp, error) {
+	return call[Resp](s, module, route, req, timeout, append([]CallOption{WithContext(ctx)}, opts...))
+}
+
+func call[Resp any](s *Service, module, route string, req any, timeout int, opts []CallOption) (Resp, error) {
+	var out Resp
+	codec, err := getCodec(s.cfg.getBase().Request.Codec)
+	if err != nil {
+		return out, fmt.Errorf("[Call %s.%s] %v", module, route, err)
+	}
+	params, err := encodeContent(req, codec)
+	if err != nil {
+		return out, fmt.Errorf("[Call %s.%s] marshal request failed: %v", module, route, err)
+	}
+	resp := s.sendOut(&Outbound{Kind: EOutKindRequest, Module: module, Route: route, Content: params, Timeout: timeout, Codec: codec.Name(), contentType: reflect.TypeOf(req)}, opts...)
+	return decodeResp[Resp](resp, codec)
+}
+
+// decodeResp 解析响应内容
+func decodeResp[Resp any](resp easyCon.PackResp, codec ICodec) (Resp, error) {
+	var out Resp
+	if err := ParseError(resp); err != nil {
+		return out, err
+	}
+
+	t := reflect.TypeOf(&out).Elem()
+	// 无返回值的方法响应内容为空
+	if len(resp.Content) == 0 && !isRawType(t) {
+		return out, nil
+	}
+	v, err := decodeContent(t, resp.Content, codec)
+	if err != nil {
+		return out, fmt.Errorf("[Call %s.%s] unmarshal response failed: %v", resp.From, resp.Route, err)
+	}
+	reflect.ValueOf(&out).Elem().Set(v)
+	return out, nil
+}
@@ -0,0 +1,738 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	qfPath      = "github.com/kamioair/qf"
+	easyConPath = "github.com/qiu-tec/easy-con.golang"
+)
+
+// 可直接使用的内置类型
+var builtinTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "rune": true, "string": true, "error": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// genRoute 解析出的路由
+type genRoute struct {
+	route   string
+	name    string // 生成的函数名
+	inType  string // 入参类型，无入参时为空
+	outType string // 返回值类型，无返回值时为空
+}
+
+// source 模块包的源码信息
+type source struct {
+	dir      string
+	pkgName  string
+	pkgPath  string
+	files    []*ast.File
+	consts   map[string]string                   // 字符串常量
+	types    map[string]ast.Expr                 // 包内声明的类型
+	funcs    map[string]*ast.FuncDecl            // 包内函数
+	methods  map[string]map[string]*ast.FuncDecl // 类型名 -> 方法名 -> 方法
+	declFile map[*ast.FuncDecl]*ast.File         // 函数所在的文件
+	imports  map[*ast.File]map[string]string     // 文件 -> 导入名 -> 路径
+	routes   []genRoute                          // 解析出的路由
+
+	usedImports map[string]string // 生成代码需要的导入，路径 -> 导入名
+	warnings    []string
+}
+
+// runGen 执行 gen 命令
+func runGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	dir := fs.String("dir", ".", "模块源码目录")
+	out := fs.String("out", "", "输出目录，默认为 <模块目录>/<包名>")
+	pkg := fs.String("pkg", "", "生成的包名，默认为 <模块包名>client")
+	module := fs.String("module", "", "模块名称，默认从 Load 调用中解析")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	src, code, err := generate(*dir, pkg, module)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = filepath.Join(*dir, *pkg)
+	}
+	if err = os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(*out, "client.go")
+	if err = os.WriteFile(file, code, 0644); err != nil {
+		return err
+	}
+	for _, w := range src.warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+	fmt.Printf("generated %d routes of %s to %s\n", len(src.routes), *module, file)
+	return nil
+}
+
+// generate 解析模块目录并生成客户端代码，pkg 和 module 为空时填入默认值
+func generate(dir string, pkg, module *string) (*source, []byte, error) {
+	src, err := loadSource(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	src.routes = src.findRoutes()
+	if len(src.routes) == 0 {
+		return nil, nil, fmt.Errorf("no route found in %s", dir)
+	}
+	if *module == "" {
+		*module = src.findModuleName()
+		if *module == "" {
+			return nil, nil, fmt.Errorf("module name not found in Load call, use -module to specify")
+		}
+	}
+	if *pkg == "" {
+		*pkg = src.pkgName + "client"
+	}
+	code, err := src.render(*pkg, *module, src.routes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, code, nil
+}
+
+// loadSource 解析目录下的模块包
+func loadSource(dir string) (*source, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	var pkg *ast.Package
+	for name, p := range pkgs {
+		if name == "main" {
+			continue
+		}
+		if pkg != nil {
+			return nil, fmt.Errorf("multiple packages in %s", dir)
+		}
+		pkg = p
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("no module package in %s", dir)
+	}
+	pkgPath, err := importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &source{
+		dir:         dir,
+		pkgName:     pkg.Name,
+		pkgPath:     pkgPath,
+		consts:      map[string]string{},
+		types:       map[string]ast.Expr{},
+		funcs:       map[string]*ast.FuncDecl{},
+		methods:     map[string]map[string]*ast.FuncDecl{},
+		declFile:    map[*ast.FuncDecl]*ast.File{},
+		imports:     map[*ast.File]map[string]string{},
+		usedImports: map[string]string{qfPath: "qf", "context": "context"},
+	}
+	names := make([]string, 0, len(pkg.Files))
+	for name := range pkg.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.index(pkg.Files[name])
+	}
+	return s, nil
+}
+
+// importPath 根据 go.mod 计算目录的导入路径
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		if f, err := os.Open(filepath.Join(root, "go.mod")); err == nil {
+			module := ""
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if strings.HasPrefix(line, "module ") {
+					module = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+					break
+				}
+			}
+			_ = f.Close()
+			if module == "" {
+				return "", fmt.Errorf("module path not found in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, _ := filepath.Rel(root, abs)
+			return path.Join(module, filepath.ToSlash(rel)), nil
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("go.mod not found for %s", dir)
+		}
+	}
+}
+
+// index 收集文件中的声明
+func (s *source) index(file *ast.File) {
+	s.files = append(s.files, file)
+	imports := map[string]string{}
+	for _, im := range file.Imports {
+		p, _ := strconv.Unquote(im.Path.Value)
+		name := path.Base(p)
+		if im.Name != nil {
+			name = im.Name.Name
+		}
+		imports[name] = p
+	}
+	s.imports[file] = imports
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch sp := spec.(type) {
+				case *ast.TypeSpec:
+					s.types[sp.Name.Name] = sp.Type
+				case *ast.ValueSpec:
+					if d.Tok != token.CONST {
+						continue
+					}
+					for i, name := range sp.Names {
+						if i < len(sp.Values) {
+							if lit, ok := sp.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+								s.consts[name.Name], _ = strconv.Unquote(lit.Value)
+							}
+						}
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			s.declFile[d] = file
+			if d.Recv == nil {
+				s.funcs[d.Name.Name] = d
+				continue
+			}
+			recv := typeName(d.Recv.List[0].Type)
+			if s.methods[recv] == nil {
+				s.methods[recv] = map[string]*ast.FuncDecl{}
+			}
+			s.methods[recv][d.Name.Name] = d
+		}
+	}
+}
+
+// findRoutes 查找注册的路由
+// 支持 reg.Handle("路由", 方法)、reg.Mount("前缀", 业务对象) 以及 OnReq 中 case "路由": qf.Invoke(pack, 方法)
+// reg 需为 *qf.Reg 类型的参数、变量或结构体字段，http.Handle 等同名调用会被忽略
+func (s *source) findRoutes() []genRoute {
+	var routes []genRoute
+	seen := map[string]bool{}
+	add := func(route string, fn *ast.FuncType, file *ast.File) {
+		if seen[route] {
+			return
+		}
+		seen[route] = true
+		routes = append(routes, s.newRoute(route, fn, file))
+	}
+
+	for _, file := range s.files {
+		qfName := s.importName(file, qfPath)
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.CaseClause:
+					// OnReq 中的 case "路由": return qf.Invoke(pack, 方法)
+					call := findInvoke(node.Body, qfName)
+					if call == nil {
+						return true
+					}
+					fn, fnFile := s.resolveFunc(call.Args[1], fd, file)
+					for _, e := range node.List {
+						if route, ok := s.stringValue(e); ok {
+							add(route, fn, fnFile)
+						}
+					}
+				case *ast.CallExpr:
+					sel, ok := node.Fun.(*ast.SelectorExpr)
+					if !ok || len(node.Args) < 2 || (sel.Sel.Name != "Handle" && sel.Sel.Name != "Mount") {
+						return true
+					}
+					if !s.isReg(sel.X, fd, qfName) {
+						return true
+					}
+					name, ok := s.stringValue(node.Args[0])
+					if !ok {
+						return true
+					}
+					switch sel.Sel.Name {
+					case "Handle":
+						fn, fnFile := s.resolveFunc(node.Args[1], fd, file)
+						add(name, fn, fnFile)
+					case "Mount":
+						typ := s.resolveType(node.Args[1], fd)
+						methods := s.methods[typ]
+						if len(methods) == 0 {
+							s.warn("mount [%s] type of %s not resolved", name, exprString(node.Args[1]))
+							return true
+						}
+						var names []string
+						for m := range methods {
+							if ast.IsExported(m) {
+								names = append(names, m)
+							}
+						}
+						sort.Strings(names)
+						for _, m := range names {
+							add(name+m, methods[m].Type, s.declFile[methods[m]])
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+	return routes
+}
+
+// isReg 表达式是否为 *qf.Reg，支持函数内声明的参数和变量以及结构体字段
+func (s *source) isReg(e ast.Expr, scope *ast.FuncDecl, qfName string) bool {
+	switch v := e.(type) {
+	case *ast.ParenExpr:
+		return s.isReg(v.X, scope, qfName)
+	case *ast.Ident:
+		found := false
+		ast.Inspect(scope, func(n ast.Node) bool {
+			var names []*ast.Ident
+			var typ ast.Expr
+			switch d := n.(type) {
+			case *ast.Field:
+				names, typ = d.Names, d.Type
+			case *ast.ValueSpec:
+				names, typ = d.Names, d.Type
+			default:
+				return !found
+			}
+			for _, name := range names {
+				if name.Name == v.Name && isRegType(typ, qfName) {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	case *ast.SelectorExpr:
+		st, ok := s.types[s.resolveType(v.X, scope)].(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, f := range st.Fields.List {
+			for _, name := range f.Names {
+				if name.Name == v.Sel.Name {
+					return isRegType(f.Type, qfName)
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isRegType 类型是否为 qf.Reg 或 *qf.Reg
+func isRegType(e ast.Expr, qfName string) bool {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Reg" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == qfName
+}
+
+// findModuleName 从 Load(模块名, ...) 调用中获取模块名称
+func (s *source) findModuleName() string {
+	module := ""
+	for _, file := range s.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || module != "" || len(call.Args) != 5 {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Load" {
+				module, _ = s.stringValue(call.Args[0])
+			}
+			return true
+		})
+	}
+	return module
+}
+
+// findInvoke 查找语句中的 qf.Invoke 调用
+func findInvoke(body []ast.Stmt, qfName string) *ast.CallExpr {
+	var found *ast.CallExpr
+	for _, stmt := range body {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || found != nil || len(call.Args) != 2 {
+				return found == nil
+			}
+			fun := call.Fun
+			if idx, ok := fun.(*ast.IndexExpr); ok {
+				fun = idx.X
+			}
+			if sel, ok := fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Invoke" {
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == qfName {
+					found = call
+				}
+			}
+			return found == nil
+		})
+	}
+	return found
+}
+
+// resolveFunc 获取方法表达式对应的函数签名，无法解析时返回nil
+func (s *source) resolveFunc(e ast.Expr, scope *ast.FuncDecl, file *ast.File) (*ast.FuncType, *ast.File) {
+	switch v := e.(type) {
+	case *ast.FuncLit:
+		return v.Type, file
+	case *ast.Ident:
+		if fd, ok := s.funcs[v.Name]; ok {
+			return fd.Type, s.declFile[fd]
+		}
+	case *ast.SelectorExpr:
+		if fd, ok := s.methods[s.resolveType(v.X, scope)][v.Sel.Name]; ok {
+			return fd.Type, s.declFile[fd]
+		}
+	}
+	return nil, file
+}
+
+// resolveType 推断表达式的类型名，支持接收者、结构体字段、局部变量、构造函数和复合字面量
+func (s *source) resolveType(e ast.Expr, scope *ast.FuncDecl) string {
+	switch v := e.(type) {
+	case *ast.ParenExpr:
+		return s.resolveType(v.X, scope)
+	case *ast.UnaryExpr:
+		return s.resolveType(v.X, scope)
+	case *ast.StarExpr:
+		return s.resolveType(v.X, scope)
+	case *ast.CompositeLit:
+		return typeName(v.Type)
+	case *ast.CallExpr:
+		if id, ok := v.Fun.(*ast.Ident); ok {
+			if fd, ok := s.funcs[id.Name]; ok && fd.Type.Results != nil && len(fd.Type.Results.List) > 0 {
+				return typeName(fd.Type.Results.List[0].Type)
+			}
+		}
+	case *ast.SelectorExpr:
+		st, ok := s.types[s.resolveType(v.X, scope)].(*ast.StructType)
+		if !ok {
+			return ""
+		}
+		for _, f := range st.Fields.List {
+			for _, name := range f.Names {
+				if name.Name == v.Sel.Name {
+					return typeName(f.Type)
+				}
+			}
+		}
+	case *ast.Ident:
+		if scope.Recv != nil {
+			for _, name := range scope.Recv.List[0].Names {
+				if name.Name == v.Name {
+					return typeName(scope.Recv.List[0].Type)
+				}
+			}
+		}
+		// 局部变量 x := ...
+		typ := ""
+		ast.Inspect(scope.Body, func(n ast.Node) bool {
+			as, ok := n.(*ast.AssignStmt)
+			if !ok || typ != "" || len(as.Lhs) != len(as.Rhs) {
+				return typ == ""
+			}
+			for i, lhs := range as.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == v.Name {
+					typ = s.resolveType(as.Rhs[i], scope)
+				}
+			}
+			return typ == ""
+		})
+		return typ
+	}
+	return ""
+}
+
+// newRoute 根据函数签名生成路由，类型无法在客户端引用时入参使用 any，返回值使用 []byte
+func (s *source) newRoute(route string, fn *ast.FuncType, file *ast.File) genRoute {
+	r := genRoute{route: route, name: funcName(route)}
+	if fn == nil {
+		s.warn("route [%s] method not resolved, use any/[]byte", route)
+		r.inType, r.outType = "any", "[]byte"
+		return r
+	}
+
+	params := flatten(fn.Params)
+	if len(params) > 0 && s.isContext(params[0], file) {
+		params = params[1:]
+	}
+	if len(params) > 0 {
+		if t, ok := s.renderType(params[0], file); ok {
+			r.inType = t
+		} else {
+			s.warn("route [%s] param type %s not accessible, use any", route, exprString(params[0]))
+			r.inType = "any"
+		}
+	}
+
+	results := flatten(fn.Results)
+	if len(results) > 0 {
+		results = results[:len(results)-1]
+	}
+	if len(results) > 0 {
+		if sel, ok := results[len(results)-1].(*ast.SelectorExpr); ok && sel.Sel.Name == "EResp" && s.selectorPath(sel, file) == easyConPath {
+			results = results[:len(results)-1]
+		}
+	}
+	if len(results) > 0 {
+		if t, ok := s.renderType(results[0], file); ok {
+			r.outType = t
+		} else {
+			s.warn("route [%s] return type %s not accessible, use []byte", route, exprString(results[0]))
+			r.outType = "[]byte"
+		}
+	}
+	return r
+}
+
+// isContext 参数是否为 qf.IContext 或 context.Context
+func (s *source) isContext(e ast.Expr, file *ast.File) bool {
+	sel, ok := e.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	p := s.selectorPath(sel, file)
+	return (p == qfPath && sel.Sel.Name == "IContext") || (p == "context" && sel.Sel.Name == "Context")
+}
+
+// renderType 生成类型在客户端包中的写法，类型不可访问时返回false
+func (s *source) renderType(e ast.Expr, file *ast.File) (string, bool) {
+	switch v := e.(type) {
+	case *ast.Ident:
+		if _, ok := s.types[v.Name]; ok {
+			if !ast.IsExported(v.Name) {
+				return "", false
+			}
+			s.usedImports[s.pkgPath] = s.pkgName
+			return s.pkgName + "." + v.Name, true
+		}
+		return v.Name, builtinTypes[v.Name]
+	case *ast.SelectorExpr:
+		p := s.selectorPath(v, file)
+		if p == "" {
+			return "", false
+		}
+		x := v.X.(*ast.Ident).Name
+		s.usedImports[p] = x
+		return x + "." + v.Sel.Name, true
+	case *ast.StarExpr:
+		t, ok := s.renderType(v.X, file)
+		return "*" + t, ok
+	case *ast.ArrayType:
+		t, ok := s.renderType(v.Elt, file)
+		if v.Len == nil {
+			return "[]" + t, ok
+		}
+		if lit, isLit := v.Len.(*ast.BasicLit); isLit {
+			return "[" + lit.Value + "]" + t, ok
+		}
+	case *ast.MapType:
+		k, ok1 := s.renderType(v.Key, file)
+		t, ok2 := s.renderType(v.Value, file)
+		return "map[" + k + "]" + t, ok1 && ok2
+	case *ast.InterfaceType:
+		return "any", len(v.Methods.List) == 0
+	}
+	return "", false
+}
+
+// render 生成客户端代码
+func (s *source) render(pkg, module string, routes []genRoute) ([]byte, error) {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by qf gen from %s. DO NOT EDIT.\n\n", s.pkgPath)
+	fmt.Fprintf(b, "// Package %s %s 模块的客户端，每个路由对应一个带类型的请求方法\n", pkg, module)
+	b.WriteString("// ctx 为处理中请求的 IContext 时沿用其链路Id，不在请求处理中时传入 context.Background()\n")
+	fmt.Fprintf(b, "package %s\n\n", pkg)
+
+	paths := make([]string, 0, len(s.usedImports))
+	for p := range s.usedImports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	b.WriteString("import (\n")
+	for _, p := range paths {
+		if name := s.usedImports[p]; name != path.Base(p) {
+			fmt.Fprintf(b, "\t%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(b, "\t%q\n", p)
+		}
+	}
+	b.WriteString(")\n\n")
+
+	b.WriteString("// ModuleName 模块名称\n")
+	fmt.Fprintf(b, "const ModuleName = %q\n", module)
+
+	names := map[string]bool{"ModuleName": true}
+	for _, r := range routes {
+		if !token.IsIdentifier(r.name) || names[r.name] {
+			s.warn("route [%s] cannot be converted to a unique function name, skipped", r.route)
+			continue
+		}
+		names[r.name] = true
+
+		args, req := "", "nil"
+		if r.inType != "" {
+			args, req = fmt.Sprintf(", req %s", r.inType), "req"
+		}
+		fmt.Fprintf(b, "\n// %s 请求 %s.%s\n", r.name, module, r.route)
+		if r.outType == "" {
+			fmt.Fprintf(b, "func %s(ctx context.Context, s *qf.Service%s, opts ...qf.CallOption) error {\n", r.name, args)
+			fmt.Fprintf(b, "\t_, err := qf.CallContext[[]byte](ctx, s, ModuleName, %q, %s, opts...)\n\treturn err\n}\n", r.route, req)
+		} else {
+			fmt.Fprintf(b, "func %s(ctx context.Context, s *qf.Service%s, opts ...qf.CallOption) (%s, error) {\n", r.name, args, r.outType)
+			fmt.Fprintf(b, "\treturn qf.CallContext[%s](ctx, s, ModuleName, %q, %s, opts...)\n}\n", r.outType, r.route, req)
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+// importName 获取文件中导入路径对应的名称
+func (s *source) importName(file *ast.File, p string) string {
+	for name, ip := range s.imports[file] {
+		if ip == p {
+			return name
+		}
+	}
+	return ""
+}
+
+// selectorPath 获取 pkg.Name 形式的类型所在的导入路径
+func (s *source) selectorPath(sel *ast.SelectorExpr, file *ast.File) string {
+	if x, ok := sel.X.(*ast.Ident); ok {
+		return s.imports[file][x.Name]
+	}
+	return ""
+}
+
+// stringValue 获取字符串字面量或常量的值
+func (s *source) stringValue(e ast.Expr) (string, bool) {
+	switch v := e.(type) {
+	case *ast.BasicLit:
+		if v.Kind == token.STRING {
+			str, err := strconv.Unquote(v.Value)
+			return str, err == nil
+		}
+	case *ast.Ident:
+		str, ok := s.consts[v.Name]
+		return str, ok
+	}
+	return "", false
+}
+
+func (s *source) warn(format string, args ...any) {
+	s.warnings = append(s.warnings, fmt.Sprintf(format, args...))
+}
+
+// flatten 展开参数列表，a, b T 展开为两个 T
+func flatten(fl *ast.FieldList) []ast.Expr {
+	if fl == nil {
+		return nil
+	}
+	var list []ast.Expr
+	for _, f := range fl.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			list = append(list, f.Type)
+		}
+	}
+	return list
+}
+
+// typeName 获取类型表达式的类型名，忽略指针和泛型参数
+func typeName(e ast.Expr) string {
+	switch v := e.(type) {
+	case *ast.StarExpr:
+		return typeName(v.X)
+	case *ast.IndexExpr:
+		return typeName(v.X)
+	case *ast.IndexListExpr:
+		return typeName(v.X)
+	case *ast.Ident:
+		return v.Name
+	}
+	return ""
+}
+
+// funcName 将路由名转换为导出的函数名，如 device/get_info 转换为 DeviceGetInfo
+func funcName(route string) string {
+	b := strings.Builder{}
+	upper := true
+	for _, r := range route {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// exprString 表达式的简要描述，用于提示信息
+func exprString(e ast.Expr) string {
+	switch v := e.(type) {
+	case *ast.Ident:
+		return v.Name
+	case *ast.SelectorExpr:
+		return exprString(v.X) + "." + v.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(v.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(v.Elt)
+	}
+	return fmt.Sprintf("%T", e)
+}
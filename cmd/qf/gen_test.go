@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新 golden 文件")
+
+// checkGolden 生成模块目录的客户端代码并与 golden 文件比较，-update 时写入 golden 文件
+func checkGolden(t *testing.T, dir, golden string) {
+	t.Helper()
+	pkg, module := "", ""
+	src, code, err := generate(dir, &pkg, &module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(src.warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", src.warnings)
+	}
+	if *update {
+		if err = os.WriteFile(golden, code, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, want) {
+		t.Errorf("generated code differs from %s:\n%s", golden, code)
+	}
+}
+
+func TestGenExample(t *testing.T) {
+	dir := filepath.Join("..", "..", "example")
+	checkGolden(t, dir, filepath.Join(dir, "exampleclient", "client.go"))
+}
+
+func TestGenOnReq(t *testing.T) {
+	checkGolden(t, filepath.Join("testdata", "legacy"), filepath.Join("testdata", "legacy.golden"))
+}
@@ -0,0 +1,60 @@
+package legacy
+
+import (
+	"net/http"
+
+	"github.com/kamioair/qf"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+)
+
+const (
+	Name     = "LegacyModule"
+	RouteGet = "GetUser"
+)
+
+type User struct {
+	Id   int
+	Name string
+}
+
+type Service struct {
+	qf.Service
+	mux *http.ServeMux
+}
+
+func NewService() *Service {
+	s := &Service{mux: http.NewServeMux()}
+	s.Load(Name, "legacy module", "V1.0.0", "", nil)
+	return s
+}
+
+func (s *Service) Reg(reg *qf.Reg) {
+	reg.OnReq = s.onReq
+	reg.Handle("Echo", func(in string) (string, error) {
+		return in, nil
+	})
+
+	// 不是 qf.Reg 的 Handle 不是路由
+	s.mux.Handle("/status", http.NotFoundHandler())
+	http.Handle("/metrics", http.NotFoundHandler())
+}
+
+func (s *Service) onReq(pack easyCon.PackReq) (easyCon.EResp, []byte) {
+	switch pack.Route {
+	case RouteGet:
+		return qf.Invoke(pack, s.getUser)
+	case "Save", "Update":
+		return qf.Invoke(pack, s.save)
+	case "Ping":
+		return qf.Invoke(pack, func() error { return nil })
+	}
+	return easyCon.ERespRouteNotFind, nil
+}
+
+func (s *Service) getUser(id int) (User, error) {
+	return User{Id: id}, nil
+}
+
+func (s *Service) save(ctx qf.IContext, user User) (bool, easyCon.EResp, error) {
+	return true, easyCon.ERespSuccess, nil
+}
@@ -0,0 +1,139 @@
+package qf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"sync"
+)
+
+// ICodec 负载编解码接口
+type ICodec interface {
+	// Name 编解码名称，随请求传递给被调用方
+	Name() string
+	// Marshal 编码
+	Marshal(v any) ([]byte, error)
+	// Unmarshal 解码
+	Unmarshal(data []byte, v any) error
+}
+
+const (
+	CodecJson    = "json"
+	CodecMsgPack = "msgpack"
+	CodecCbor    = "cbor"
+)
+
+var (
+	codecs     = map[string]ICodec{}
+	codecsLock = sync.RWMutex{}
+)
+
+func init() {
+	RegCodec(jsonCodec{})
+	RegCodec(msgpackCodec{})
+	RegCodec(cborCodec{})
+}
+
+// RegCodec 注册编解码，可用于扩展 protobuf 等格式，同名时覆盖
+// 收发双方都需要注册同名的编解码
+func RegCodec(codec ICodec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// getCodec 获取编解码，名称为空时使用JSON
+func getCodec(name string) (ICodec, error) {
+	if name == "" {
+		name = CodecJson
+	}
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("codec [%s] not registered", name)
+	}
+	return codec, nil
+}
+
+// withStrict 设置是否严格解析，仅对JSON生效
+func withStrict(codec ICodec, strict bool) ICodec {
+	if _, ok := codec.(jsonCodec); ok {
+		return jsonCodec{strict: strict}
+	}
+	return codec
+}
+
+// unmarshalJson 解析JSON，严格模式下不允许未知字段，数字按 json.Number 解析
+func unmarshalJson(data []byte, v any, strict bool) error {
+	if !strict {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+	return nil
+}
+
+// jsonCodec JSON编解码，严格模式下不允许未知字段，数字按 json.Number 解析
+type jsonCodec struct {
+	strict bool
+}
+
+func (c jsonCodec) Name() string {
+	return CodecJson
+}
+
+func (c jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c jsonCodec) Unmarshal(data []byte, v any) error {
+	return unmarshalJson(data, v, c.strict)
+}
+
+// msgpackCodec MessagePack编解码，字段名与JSON保持一致
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string {
+	return CodecMsgPack
+}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// cborCodec CBOR编解码，未设置 cbor 标签时使用 json 标签
+type cborCodec struct{}
+
+func (cborCodec) Name() string {
+	return CodecCbor
+}
+
+func (cborCodec) Marshal(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (cborCodec) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
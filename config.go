@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/kamioair/utils/qconfig"
 	"github.com/kamioair/utils/qio"
+	easyCon "github.com/qiu-tec/easy-con.golang"
 	"os"
 )
 
@@ -29,6 +30,33 @@ type Config struct {
 		IsRandomClientID  bool   // 是否随机clientID
 		IsSyncMode        bool   // 是否同步模式
 	} `comment:"MqBroker\n Addr:访问地址\n UId,Pwd:登录账号密码\n TimeOut:请求超时(毫秒)\n Retry:重试次数\n LogMode:日志模式 NONE/CONSOLE\n Prefix:前缀，用于同一个模块不同实例\n ChannelBufferSize: 各种消息通道的缓冲区大小\n ConnectRetryDelay: 连接重试之间的延迟(毫秒)\n LinkTimeOut:连接等待超时(毫秒) 0表示无限等待直到连上\n IsRandomClientID:是否随机clientID\n IsSyncMode:是否请求同步模式，启用后所有请求无法并行，只能一个一个执行"` // 服务连接配置
+	Request struct {
+		StrictJson       bool    // 是否严格解析JSON
+		Header           bool    // 是否随请求发送请求头
+		Codec            string  // qf.Call 和 qf.Publish 使用的编解码
+		RetryMaxAttempts int     // 请求最大尝试次数
+		RetryBaseDelay   int     // 首次重试等待时间（毫秒）
+		RetryMaxDelay    int     // 最大重试等待时间（毫秒）
+		RetryJitter      float64 // 重试等待时间的随机抖动比例
+		RetryCodes       []int   // 可重试的响应码
+		BreakerThreshold int     // 熔断阈值
+		BreakerOpenTime  int     // 熔断时长（毫秒）
+	} `comment:"请求处理\n StrictJson:是否严格解析JSON，启用后请求中包含未知字段时返回BadReq，数字按json.Number解析\n Header:是否随请求发送请求头(超时时间、元数据、链路Id等)，请求头以 \\x1bqf\\x1b+JSON+换行 的形式放在请求内容前，被调用方需为支持请求头的qf版本，否则会当作请求内容解析，调用旧版本模块或其他easyCon客户端时不要启用\n Codec:qf.Call发送请求和qf.Publish发送通知时使用的编解码 json/msgpack/cbor，被调用方按请求的编解码解析和响应，订阅方按通知的编解码解析，非JSON时内容前带有编解码标识，接收方需为支持编解码的qf版本，SendRequest/SendNotice等直接发送字节的方法不使用\n RetryMaxAttempts:发送请求的最大尝试次数(含首次)，1表示不重试，在Broker.Retry之外额外重试\n RetryBaseDelay,RetryMaxDelay:重试等待时间(毫秒)，每次翻倍直到最大值，RetryMaxDelay为0时不限制\n RetryJitter:重试等待时间的随机抖动比例 0~1\n RetryCodes:可重试的响应码，非幂等请求仅在未连接(0)时重试\n BreakerThreshold:同一目标路由连续超时达到该次数后熔断，熔断期间请求直接返回503，0表示不启用\n BreakerOpenTime:熔断时长(毫秒)，到期后放行一个探测请求，成功则恢复，失败则继续熔断\n 熔断状态可通过内置路由 Breakers 查询"` // 请求处理配置
+	Log struct {
+		FileLevel      string   // 写入文件的最低级别
+		ConsoleLevel   string   // 输出到控制台的最低级别
+		BusLevel       string   // 发送到总线的最低级别
+		Dir            string   // 日志文件目录
+		MaxSize        int      // 单个文件最大大小（MB）
+		MaxAge         int      // 历史文件保留天数
+		MaxFiles       int      // 历史文件保留个数
+		Compress       bool     // 是否压缩历史文件
+		FileFormat     string   // 文件日志格式
+		ConsoleFormat  string   // 控制台日志格式
+		RedactFields   []string // 脱敏的字段名或路径
+		RedactPatterns []string // 脱敏的正则
+		MaxPayload     int      // 日志中参数内容的最大长度（字节）
+	} `comment:"日志\n FileLevel,ConsoleLevel,BusLevel:文件、控制台、总线各自输出的最低级别 DEBUG/INFO/WARN/ERROR，NONE表示不输出\n Dir:日志文件目录，当前日志为 模块名.log，超过大小或跨天时滚动为 模块名-时间.log\n MaxSize:单个文件最大大小(MB)，0表示不限制\n MaxAge,MaxFiles:历史文件保留的天数和个数，0表示不限制，启动和滚动时清理，旧版本的 yyyy-MM/dd_模块名_级别.log 按MaxAge清理\n Compress:是否gzip压缩历史文件\n FileFormat,ConsoleFormat:文件和控制台的日志格式 text/json，json 为每行一个JSON(JSON Lines)，包含 timestamp/level/msg/module/caller 及 route/error/stack 等字段\n RedactFields:日志中参数需脱敏的JSON字段，不含.时按字段名匹配，含.时按从根开始的路径匹配如 user.password，不区分大小写，数组元素不计入路径，参数类型已知时其中带 qf:secret 标签的字段按所在路径脱敏\n RedactPatterns:日志中参数需脱敏的正则，匹配的内容替换为***\n MaxPayload:日志中参数内容的最大长度(字节)，超出部分截断，0表示不限制"` // 日志配置
 }
 
 type emptyConfig struct {
@@ -103,6 +131,28 @@ func loadConfig(config IConfig) *Config {
 		IsRandomClientID:  false,
 		IsSyncMode:        false,
 	}
+	baseCfg.Request.StrictJson = false
+	baseCfg.Request.Header = false
+	baseCfg.Request.Codec = CodecJson
+	baseCfg.Request.RetryMaxAttempts = 1
+	baseCfg.Request.RetryBaseDelay = 100
+	baseCfg.Request.RetryMaxDelay = 2000
+	baseCfg.Request.RetryJitter = 0.2
+	baseCfg.Request.RetryCodes = []int{int(easyCon.ERespTimeout), int(easyCon.ERespUnLinked)}
+	baseCfg.Request.BreakerThreshold = 0
+	baseCfg.Request.BreakerOpenTime = 10000
+	baseCfg.Log.FileLevel = "ERROR"
+	baseCfg.Log.ConsoleLevel = "DEBUG"
+	baseCfg.Log.BusLevel = "DEBUG"
+	baseCfg.Log.Dir = "./log"
+	baseCfg.Log.MaxSize = 10
+	baseCfg.Log.MaxAge = 30
+	baseCfg.Log.MaxFiles = 20
+	baseCfg.Log.Compress = true
+	baseCfg.Log.FileFormat = LogFormatText
+	baseCfg.Log.ConsoleFormat = LogFormatText
+	baseCfg.Log.RedactFields = []string{"password", "pwd", "token", "secret"}
+	baseCfg.Log.MaxPayload = 2048
 	err = qconfig.LoadConfig(baseCfg.filePath, "Base", baseCfg)
 	if err != nil {
 		panic(fmt.Sprintf("load config file [%s] failed: %v", qio.GetFullPath(baseCfg.filePath), err))
@@ -1,6 +1,7 @@
 package qf
 
 import (
+	goContext "context"
 	"encoding/json"
 	"fmt"
 	easyCon "github.com/qiu-tec/easy-con.golang"
@@ -8,23 +9,32 @@ import (
 )
 
 type context struct {
+	goContext.Context
 	raw        string
 	reqPack    *easyCon.PackReq
 	respPack   *easyCon.PackResp
 	noticePack *easyCon.PackNotice
+	codec      ICodec            // 请求内容的编解码，为nil时使用JSON
+	meta       map[string]string // 调用方传递的元数据
 }
 
 // NewContent 创建上下文
 func NewContent(value any) (IContext, error) {
-	return newContent(value, nil, nil, nil)
+	ctx, err := newContent(value, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ctx, nil
 }
 
-func newContent(value any, reqPack *easyCon.PackReq, respPack *easyCon.PackResp, noticePack *easyCon.PackNotice) (IContext, error) {
+func newContent(value any, reqPack *easyCon.PackReq, respPack *easyCon.PackResp, noticePack *easyCon.PackNotice) (*context, error) {
 	var raw string
 
 	switch v := value.(type) {
 	case string:
 		raw = v
+	case []byte:
+		raw = string(v)
 	default:
 		js, err := json.Marshal(value)
 		if err != nil {
@@ -34,6 +44,7 @@ func newContent(value any, reqPack *easyCon.PackReq, respPack *easyCon.PackResp,
 	}
 
 	ctx := &context{
+		Context:    goContext.Background(),
 		raw:        raw,
 		reqPack:    reqPack,
 		respPack:   respPack,
@@ -42,10 +53,84 @@ func newContent(value any, reqPack *easyCon.PackReq, respPack *easyCon.PackResp,
 	return ctx, nil
 }
 
+// newReqContext 创建请求上下文
+func newReqContext(parent goContext.Context, pack *easyCon.PackReq) *context {
+	return &context{
+		Context: parent,
+		raw:     string(pack.Content),
+		reqPack: pack,
+	}
+}
+
+func (c *context) Meta(key string) string {
+	return c.meta[key]
+}
+
+func (c *context) TraceId() string {
+	if t := traceOf(c.Context); t != nil {
+		return t.traceId
+	}
+	return ""
+}
+
+// payloadCodec 获取请求内容的编解码
+func (c *context) payloadCodec() ICodec {
+	if c.codec == nil {
+		return jsonCodec{}
+	}
+	return c.codec
+}
+
 func (c *context) Raw() string {
 	return c.raw
 }
 
+func (c *context) From() string {
+	switch {
+	case c.reqPack != nil:
+		return c.reqPack.From
+	case c.respPack != nil:
+		return c.respPack.From
+	case c.noticePack != nil:
+		return c.noticePack.From
+	}
+	return ""
+}
+
+func (c *context) Route() string {
+	switch {
+	case c.reqPack != nil:
+		return c.reqPack.Route
+	case c.respPack != nil:
+		return c.respPack.Route
+	case c.noticePack != nil:
+		return c.noticePack.Route
+	}
+	return ""
+}
+
+func (c *context) Id() uint64 {
+	switch {
+	case c.reqPack != nil:
+		return c.reqPack.Id
+	case c.respPack != nil:
+		return c.respPack.Id
+	case c.noticePack != nil:
+		return c.noticePack.Id
+	}
+	return 0
+}
+
+func (c *context) ReqTime() string {
+	switch {
+	case c.reqPack != nil:
+		return c.reqPack.ReqTime
+	case c.respPack != nil:
+		return c.respPack.ReqTime
+	}
+	return ""
+}
+
 func (c *context) Bind(refStruct any) error {
 	// 安全检查：确保 refStruct 不为 nil
 	if refStruct == nil {
@@ -59,7 +144,7 @@ func (c *context) Bind(refStruct any) error {
 
 	raw := c.raw
 	// 先尝试直接解析为JSON
-	err := json.Unmarshal([]byte(raw), refStruct)
+	err := c.payloadCodec().Unmarshal([]byte(raw), refStruct)
 	if err == nil {
 		return nil
 	}
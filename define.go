@@ -1,17 +1,15 @@
 package qf
 
 import (
+	goContext "context"
 	"encoding/json"
 	"fmt"
-	"github.com/kamioair/utils/qconvert"
-	"github.com/kamioair/utils/qio"
 	easyCon "github.com/qiu-tec/easy-con.golang"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
 	"strings"
-	"time"
 )
 
 // IModule 模块入口接口
@@ -35,7 +33,9 @@ type IService interface {
 
 	// 内部使用的方法
 	config() IConfig
-	setEnv(reg *Reg, adapter easyCon.IAdapter)
+	setEnv(reg *Reg, adapter easyCon.IAdapter, ctx goContext.Context)
+	breakerStates() []BreakerState
+	closeLogger()
 }
 
 // IConfig 配置接口
@@ -50,10 +50,17 @@ type ICrypto interface {
 	Decrypt(content string) (string, error)
 }
 
-// IContext 上下文
+// IContext 上下文，同时实现了 context.Context，可直接传给数据库等需要 context 的调用
 type IContext interface {
-	Raw() string
-	Bind(refStruct any) error
+	goContext.Context
+	Raw() string              // 原始内容
+	Bind(refStruct any) error // 将内容解析到结构体
+	From() string             // 来源模块
+	Route() string            // 请求或通知的路由
+	Id() uint64               // 请求或通知的Id
+	ReqTime() string          // 请求发起时间
+	Meta(key string) string   // 调用方随请求传递的元数据
+	TraceId() string          // 请求的链路Id，通知时为空
 }
 
 // Void 空值
@@ -69,8 +76,23 @@ type Reg struct {
 	OnRetainNotice  func(notice easyCon.PackNotice)
 	OnStatusChanged func(status easyCon.EStatus)
 	OnLog           func(log easyCon.PackLog)
+
+	routes       map[string]*method // 通过 Handle 注册的路由
+	middlewares  []Middleware       // 通过 Use 注册的中间件
+	interceptors []Interceptor      // 通过 Intercept 注册的出站拦截器
+	idempotent   map[string]bool    // 通过 Idempotent 标记的幂等路由，模块名.路由名
+	subscribers  []*subscriber      // 通过 Subscribe 注册的通知订阅
+	publishes    []*publishDecl     // 通过 DeclarePublish 声明的发出通知
+	healthChecks []namedCheck       // 通过 HealthCheck 注册的存活检查
+	readyChecks  []namedCheck       // 通过 ReadyCheck 注册的就绪检查
 }
 
+// Handler 请求处理方法
+type Handler func(ctx IContext) (easyCon.EResp, []byte)
+
+// Middleware 请求中间件，可在调用 next 前后加入鉴权、限流、统计、审计等通用逻辑
+type Middleware func(next Handler) Handler
+
 // OnReqFunc 请求方法定义
 type OnReqFunc func(ctx IContext) (any, error)
 
@@ -83,99 +105,22 @@ type OnReadDelegate func([]byte)
 
 // Invoke 调用业务方法
 func Invoke[T any](pack easyCon.PackReq, method T) (code easyCon.EResp, resp []byte) {
-	defer errRecover(func(err string) {
-		code = easyCon.ERespError
-		resp = []byte(err)
-	}, pack.To, pack.Route, pack.Content)
-
 	// 验证
-	v := reflect.ValueOf(method)
-	if !v.IsValid() {
-		return easyCon.ERespError, []byte("invalid method")
-	}
-
-	t := v.Type()
-	// 检查是否是函数
-	if t.Kind() != reflect.Func {
-		return easyCon.ERespError, []byte(fmt.Sprintf("not a function: %T\n", method))
+	m, err := newMethod(method)
+	if err != nil {
+		return easyCon.ERespError, []byte(err.Error())
 	}
 
-	// 获取函数参数个数
-	numIn := t.NumIn()
-	if numIn > 1 {
-		return easyCon.ERespError, []byte(fmt.Sprintf("method %T too many arguments, expect 0 or 1", method))
-	}
-
-	var args []reflect.Value
-	var err error
-
-	if numIn > 0 {
-		paramType := t.In(0)
-		if paramType.Kind() == reflect.String {
-			args = []reflect.Value{reflect.ValueOf(string(pack.Content))}
-		} else if paramType.Kind() == reflect.Slice {
-			args = []reflect.Value{reflect.ValueOf(pack.Content)}
-		} else {
-			obj := reflect.New(paramType).Interface()
-			err = json.Unmarshal(pack.Content, &obj)
-			if err != nil {
-				return easyCon.ERespBadReq, []byte(err.Error())
-			}
-			args = []reflect.Value{reflect.ValueOf(obj).Elem()}
-		}
-	}
-
-	// 调用方法
-	results := v.Call(args)
-
-	// 处理返回
-	if len(results) == 2 {
-		code = results[0].Interface().(easyCon.EResp)
-		if code != easyCon.ERespSuccess {
-			if results[1].Interface() != nil {
-				err = results[1].Interface().(error)
-			}
-			if err == nil {
-				return code, []byte("")
-			}
-			return code, []byte(err.Error())
-		}
-		return code, nil
-	} else if len(results) == 3 {
-		code = results[1].Interface().(easyCon.EResp)
-		if code != easyCon.ERespSuccess {
-			if results[2].Interface() != nil {
-				err = results[2].Interface().(error)
-			}
-			if err == nil {
-				return code, []byte("")
-			}
-			return code, []byte(err.Error())
-		}
-		obj := results[0].Interface()
-		if obj == nil {
-			resp = []byte("")
-		} else if s, ok := obj.(string); ok {
-			// 如果是字符串，直接转换为 []byte
-			resp = []byte(s)
-		} else if b, ok := obj.([]byte); ok {
-			// 如果已经是 []byte，直接使用
-			resp = b
-		} else {
-			// 其他类型（结构体等）转换为 JSON 格式的 []byte
-			var err error
-			resp, err = json.Marshal(obj)
-			if err != nil {
-				return easyCon.ERespError, []byte(fmt.Sprintf("failed to marshal response: %v", err))
-			}
-		}
-		return code, resp
-	}
-	return easyCon.ERespError, []byte("invalid return count, need any,code,error or code,error")
+	defer errRecover(lookupReqCtx(&pack), func(err string) {
+		code = easyCon.ERespError
+		resp = []byte(err)
+	}, pack.To, pack.Route, logParam{content: pack.Content, typ: m.inType})
+	return m.call(lookupReqCtx(&pack), pack)
 }
 
 // @Description: Panic的异常收集
-func errRecover(after func(err string), moduleName string, route string, inParam any) {
+// ctx 中带有链路信息时记录到日志中
+func errRecover(ctx goContext.Context, after func(err string), moduleName string, route string, inParam any) {
 	if r := recover(); r != nil {
 		// 获取异常
 		var buf [4096]byte
@@ -206,8 +151,18 @@ func errRecover(after func(err string), moduleName string, route string, inParam
 		}
 
 		// 记录错误日志
-		str, _ := json.Marshal(inParam)
-		writeLog(moduleName, "Error", fmt.Sprintf("[%s] InParam=%s", route, str), log)
+		var str string
+		switch v := inParam.(type) {
+		case nil:
+		case logParam:
+			str = logPayload(moduleName, v.content, v.typ)
+		case []byte:
+			str = logPayload(moduleName, v, nil)
+		default:
+			js, _ := json.Marshal(v)
+			str = logPayload(moduleName, js, reflect.TypeOf(v))
+		}
+		loggerOf(moduleName).ErrorContext(ctx, "panic recovered", "route", route, "inParam", str, "error", fmt.Sprint(r), "stack", log)
 	}
 }
 
@@ -220,17 +175,3 @@ func formatStack(name string, row string) string {
 	}
 	return fmt.Sprintf("   %s\n      %s\n", funcName, sp[0])
 }
-
-func writeLog(module string, level string, content string, err string) {
-	now := time.Now()
-	temp := "{Time} [{Level}] {Error} {Content}"
-	log := strings.Replace(temp, "{Time}", qconvert.Time.ToString(now, "yyyy-MM-dd HH:mm:ss"), 1)
-	log = strings.Replace(log, "{Level}", level, 1)
-	log = strings.Replace(log, "{Error}", err, 1)
-	log = strings.Replace(log, "{Content}", content, 1)
-	ym := qconvert.Time.ToString(now, "yyyy-MM")
-	day := qconvert.Time.ToString(now, "dd")
-	logFile := fmt.Sprintf("%s/%s/%s_%s_%s.log", "./log", ym, day, module, level)
-	logFile = qio.GetFullPath(logFile)
-	_ = qio.WriteString(logFile, log+"\n", true)
-}
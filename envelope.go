@@ -0,0 +1,62 @@
+package qf
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// 请求头标识
+// easyCon 的请求包没有可扩展的头部，附加信息以 标识+头部JSON+换行 的形式放在请求内容前
+// 不支持请求头的模块会将其当作请求内容，因此只在启用 Base.Request.Header 或使用非JSON编解码时发送
+var envelopeMagic = []byte("\x1bqf\x1b")
+
+// reqHeader 随请求传递的附加信息
+type reqHeader struct {
+	Timeout int               `json:",omitempty"` // 调用方的超时时间（毫秒）
+	Codec   string            `json:",omitempty"` // 请求和响应内容的编解码，为空时为JSON
+	Meta    map[string]string `json:",omitempty"` // 调用方传递的元数据
+	TraceId string            `json:",omitempty"` // 链路Id
+}
+
+// isEmpty 是否没有任何附加信息
+func (h *reqHeader) isEmpty() bool {
+	return h.Timeout <= 0 && (h.Codec == "" || h.Codec == CodecJson) && len(h.Meta) == 0 && h.TraceId == ""
+}
+
+// packEnvelope 将请求头附加到请求内容前，没有附加信息时原样返回
+func packEnvelope(header reqHeader, content []byte) []byte {
+	if header.isEmpty() {
+		return content
+	}
+	js, err := json.Marshal(header)
+	if err != nil {
+		return content
+	}
+	buf := make([]byte, 0, len(envelopeMagic)+len(js)+1+len(content))
+	buf = append(buf, envelopeMagic...)
+	buf = append(buf, js...)
+	buf = append(buf, '\n')
+	return append(buf, content...)
+}
+
+// packNoticeCodec 非JSON编解码的通知在内容前带上编解码标识，JSON通知原样发送
+func packNoticeCodec(out *Outbound) []byte {
+	return packEnvelope(reqHeader{Codec: out.Codec}, out.Content)
+}
+
+// unpackEnvelope 拆分请求头和请求内容，不带请求头时原样返回内容
+func unpackEnvelope(content []byte) (reqHeader, []byte) {
+	header := reqHeader{}
+	if !bytes.HasPrefix(content, envelopeMagic) {
+		return header, content
+	}
+	rest := content[len(envelopeMagic):]
+	end := bytes.IndexByte(rest, '\n')
+	if end < 0 {
+		return header, content
+	}
+	if err := json.Unmarshal(rest[:end], &header); err != nil {
+		return reqHeader{}, content
+	}
+	return header, rest[end+1:]
+}
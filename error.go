@@ -0,0 +1,132 @@
+package qf
+
+import (
+	"bytes"
+	goContext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+)
+
+// Error 请求失败时的错误，携带响应码
+// 业务方法返回 *Error 时会序列化为结构化的响应内容，调用方可通过 ParseError 还原后用 errors.As 获取
+type Error struct {
+	Code    easyCon.EResp // 响应码
+	Message string        // 错误信息
+	Details any           `json:",omitempty"` // 错误详情
+	Cause   error         `json:"-"`          // 原始错误，不随响应传递
+}
+
+// NewError 创建指定响应码的错误
+func NewError(code easyCon.EResp, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// BadRequest 请求参数错误
+func BadRequest(format string, args ...any) *Error {
+	return NewError(easyCon.ERespBadReq, format, args...)
+}
+
+// NotFound 请求的资源不存在
+func NotFound(format string, args ...any) *Error {
+	return NewError(easyCon.ERespRouteNotFind, format, args...)
+}
+
+// Timeout 处理超时
+func Timeout(format string, args ...any) *Error {
+	return NewError(easyCon.ERespTimeout, format, args...)
+}
+
+// Internal 内部错误
+func Internal(format string, args ...any) *Error {
+	return NewError(easyCon.ERespError, format, args...)
+}
+
+// WithDetails 设置错误详情
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+// WithCause 设置原始错误
+func (e *Error) WithCause(cause error) *Error {
+	e.Cause = cause
+	return e
+}
+
+func (e *Error) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	if e.Cause != nil {
+		return fmt.Sprintf("%s, Cause=%v", formatRespError(e.Code, e.Message), e.Cause)
+	}
+	return formatRespError(e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Cause
+}
+
+// ParseError 从响应中还原错误，响应成功时返回nil
+func ParseError(resp easyCon.PackResp) error {
+	if resp.RespCode == easyCon.ERespSuccess {
+		return nil
+	}
+	if e := parseErrorContent(resp.RespCode, resp.Content); e != nil {
+		return e
+	}
+	return &Error{Code: resp.RespCode, Message: string(resp.Content)}
+}
+
+// errorCode 根据错误推断响应码，返回值为nil的 *Error 时视为成功
+func errorCode(err error) easyCon.EResp {
+	var e *Error
+	switch {
+	case err == nil:
+		return easyCon.ERespSuccess
+	case errors.As(err, &e):
+		if e == nil {
+			return easyCon.ERespSuccess
+		}
+		return e.Code
+	case errors.Is(err, goContext.DeadlineExceeded):
+		return easyCon.ERespTimeout
+	}
+	return easyCon.ERespError
+}
+
+// encodeError 将错误转换为响应内容，*Error 序列化为结构化内容，其他错误返回错误信息
+func encodeError(code easyCon.EResp, err error) []byte {
+	var e *Error
+	if err == nil {
+		return []byte("")
+	}
+	if errors.As(err, &e) {
+		if e == nil {
+			return []byte("")
+		}
+		body := *e
+		body.Code = code
+		if js, jsErr := json.Marshal(body); jsErr == nil {
+			return js
+		}
+	}
+	return []byte(err.Error())
+}
+
+// parseErrorContent 解析结构化的错误响应内容，不是 *Error 序列化的内容时返回nil
+func parseErrorContent(code easyCon.EResp, content []byte) *Error {
+	if !bytes.HasPrefix(content, []byte("{")) {
+		return nil
+	}
+	e := &Error{}
+	if json.Unmarshal(content, e) != nil || e.Code != code {
+		return nil
+	}
+	return e
+}
@@ -2,6 +2,7 @@ package example
 
 import (
 	"fmt"
+	"github.com/kamioair/qf"
 	easyCon "github.com/qiu-tec/easy-con.golang"
 )
 
@@ -12,8 +13,8 @@ func newBll() *bll {
 	return &bll{}
 }
 
-func (b *bll) MethodA(req string) (string, easyCon.EResp, error) {
-	resp := "hello methodA"
+func (b *bll) MethodA(ctx qf.IContext, req string) (string, easyCon.EResp, error) {
+	resp := fmt.Sprintf("hello methodA, from %s", ctx.From())
 	return resp, easyCon.ERespSuccess, nil
 }
 
@@ -25,10 +26,13 @@ func (b *bll) MethodB(req string) (TestInfo, easyCon.EResp, error) {
 	return resp, easyCon.ERespSuccess, nil
 }
 
-func (b *bll) MethodC(req TestInfo) (TestInfo, easyCon.EResp, error) {
+func (b *bll) MethodC(req TestInfo) (TestInfo, error) {
+	if req.Name == "" {
+		return TestInfo{}, qf.BadRequest("name is empty")
+	}
 	resp := TestInfo{
 		Name: "MethodC",
 		Info: fmt.Sprintf("from req %s", req),
 	}
-	return resp, easyCon.ERespSuccess, nil
+	return resp, nil
 }
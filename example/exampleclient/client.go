@@ -0,0 +1,29 @@
+// Code generated by qf gen from github.com/kamioair/qf/example. DO NOT EDIT.
+
+// Package exampleclient ExampleModule 模块的客户端，每个路由对应一个带类型的请求方法
+// ctx 为处理中请求的 IContext 时沿用其链路Id，不在请求处理中时传入 context.Background()
+package exampleclient
+
+import (
+	"context"
+	"github.com/kamioair/qf"
+	"github.com/kamioair/qf/example"
+)
+
+// ModuleName 模块名称
+const ModuleName = "ExampleModule"
+
+// MethodA 请求 ExampleModule.MethodA
+func MethodA(ctx context.Context, s *qf.Service, req string, opts ...qf.CallOption) (string, error) {
+	return qf.CallContext[string](ctx, s, ModuleName, "MethodA", req, opts...)
+}
+
+// MethodB 请求 ExampleModule.MethodB
+func MethodB(ctx context.Context, s *qf.Service, req string, opts ...qf.CallOption) (example.TestInfo, error) {
+	return qf.CallContext[example.TestInfo](ctx, s, ModuleName, "MethodB", req, opts...)
+}
+
+// MethodC 请求 ExampleModule.MethodC
+func MethodC(ctx context.Context, s *qf.Service, req example.TestInfo, opts ...qf.CallOption) (example.TestInfo, error) {
+	return qf.CallContext[example.TestInfo](ctx, s, ModuleName, "MethodC", req, opts...)
+}
@@ -2,9 +2,11 @@ package example
 
 import (
 	"github.com/kamioair/qf"
-	easyCon "github.com/qiu-tec/easy-con.golang"
 )
 
+// 根据注册的路由生成带类型的客户端 exampleclient，路由或类型修改后重新执行 go generate
+//go:generate go run github.com/kamioair/qf/cmd/qf gen
+
 const (
 	Version = "V1.0.251225B01"
 	Name    = "ExampleModule"
@@ -35,6 +37,8 @@ func NewService() *Service {
 			// 自定义配置初始值
 			// ...
 		},
+		// 业务对象需在注册路由前创建
+		bll: newBll(),
 	}
 	// 加载配置，默认配置节点名称为模块名
 	// 如有需求可自定义customSectionName
@@ -45,24 +49,14 @@ func NewService() *Service {
 // Reg 注册需要执行的方法
 func (serv *Service) Reg(reg *qf.Reg) {
 	reg.OnInit = serv.onInit
-	reg.OnReq = serv.onReq
+
+	// 注册外部请求，bll的所有导出方法均作为路由
+	// 单个方法也可通过 reg.Handle("MethodA", serv.bll.MethodA) 注册
+	reg.Mount("", serv.bll)
 }
 
 // 初始化
 func (serv *Service) onInit() {
 	// 内部业务初始化
-	serv.bll = newBll()
-}
-
-// 实现外部请求
-func (serv *Service) onReq(pack easyCon.PackReq) (easyCon.EResp, []byte) {
-	switch pack.Route {
-	case "MethodA":
-		return qf.Invoke(pack, serv.bll.MethodA)
-	case "MethodB":
-		return qf.Invoke(pack, serv.bll.MethodB)
-	case "MethodC":
-		return qf.Invoke(pack, serv.bll.MethodC)
-	}
-	return serv.ReturnNotFind()
+	// ...
 }
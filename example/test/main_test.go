@@ -1,9 +1,11 @@
 package test
 
 import (
+	"context"
 	"fmt"
 	"github.com/kamioair/qf"
 	"github.com/kamioair/qf/example"
+	"github.com/kamioair/qf/example/exampleclient"
 	easyCon "github.com/qiu-tec/easy-con.golang"
 	"testing"
 )
@@ -22,17 +24,25 @@ func TestName(t *testing.T) {
 	}
 	fmt.Println("===> SendRequest MethodA Resp", respA.Content)
 
-	respB := testServ.SendRequest(exampleServ.Name(), "MethodB", respA.Content)
-	if respB.RespCode != easyCon.ERespSuccess {
-		t.Fatal(respB.Content)
+	// 使用 qf.Call 直接得到业务类型
+	respB, err := qf.Call[example.TestInfo](&testServ.Service, exampleServ.Name(), "MethodB", respA.Content)
+	if err != nil {
+		t.Fatal(err)
 	}
-	fmt.Println("===> SendRequest MethodB Resp", respB.Content)
+	fmt.Println("===> Call MethodB Resp", respB)
 
-	respC := testServ.SendRequest(exampleServ.Name(), "MethodC", respB.Content)
-	if respC.RespCode != easyCon.ERespSuccess {
-		t.Fatal(respC.Content)
+	respC, err := qf.Call[example.TestInfo](&testServ.Service, exampleServ.Name(), "MethodC", respB)
+	if err != nil {
+		t.Fatal(err)
 	}
-	fmt.Println("===> SendRequest MethodC Resp", respC.Content)
+	fmt.Println("===> Call MethodC Resp", respC)
+
+	// 使用 qf gen 生成的客户端，路由名称和类型在编译期检查
+	respC, err = exampleclient.MethodC(context.Background(), &testServ.Service, respB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println("===> exampleclient MethodC Resp", respC)
 
 	// 不退出
 	select {}
@@ -1,10 +1,12 @@
 module github.com/kamioair/qf
 
-go 1.20
+go 1.21
 
 require (
+	github.com/fxamacker/cbor/v2 v2.9.0
 	github.com/kamioair/utils v0.1.1
 	github.com/qiu-tec/easy-con.golang v0.5.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
 )
 
 require (
@@ -26,6 +28,8 @@ require (
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/spf13/viper v1.19.0 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
 	go.uber.org/multierr v1.9.0 // indirect
 	golang.org/x/exp v0.0.0-20230905200255-921286631fa9 // indirect
@@ -0,0 +1,142 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthCheck 健康检查方法，返回nil表示正常，需在 ctx 取消后尽快返回
+type HealthCheck func(ctx goContext.Context) error
+
+const (
+	HealthUp   = "UP"
+	HealthDown = "DOWN"
+)
+
+// HealthReport 健康检查结果，通过内置路由 Health 和 Ready 获取
+// 无论是否正常响应码均为成功，调用方通过 Status 判断
+type HealthReport struct {
+	Module string
+	Status string        // 所有检查均正常时为 UP，否则为 DOWN
+	Checks []CheckResult // 各项检查结果，按名称排序
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Name    string
+	Status  string
+	Latency float64 // 耗时（毫秒）
+	Error   string  `json:",omitempty"`
+}
+
+// namedCheck 已注册的检查
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthCheck 注册存活检查，由内置路由 Health 执行，用于判断模块是否需要重启
+func (reg *Reg) HealthCheck(name string, check HealthCheck) {
+	reg.healthChecks = addCheck(reg.healthChecks, "health", name, check)
+}
+
+// ReadyCheck 注册就绪检查（数据库、磁盘、下游模块等），由内置路由 Ready 执行
+// 模块在 OnInit 执行完成前始终未就绪
+func (reg *Reg) ReadyCheck(name string, check HealthCheck) {
+	reg.readyChecks = addCheck(reg.readyChecks, "ready", name, check)
+}
+
+// addCheck 添加检查，名称重复或方法为空时直接panic
+func addCheck(checks []namedCheck, kind, name string, check HealthCheck) []namedCheck {
+	if check == nil {
+		panic(fmt.Errorf("%s check [%s] is nil", kind, name))
+	}
+	for _, c := range checks {
+		if c.name == name {
+			panic(fmt.Errorf("%s check [%s] already registered", kind, name))
+		}
+	}
+	return append(checks, namedCheck{name: name, check: check})
+}
+
+// ModuleCheck 检查下游模块是否可访问，请求其 Version 路由，不重试
+func (bll *Service) ModuleCheck(module string) HealthCheck {
+	return func(ctx goContext.Context) error {
+		timeout := bll.cfg.getBase().Broker.TimeOut
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = int(time.Until(deadline).Milliseconds())
+		}
+		if timeout <= 0 {
+			return ctx.Err()
+		}
+		resp := bll.SendRequestWithTimeout(module, "Version", nil, timeout, WithRetry(RetryPolicy{MaxAttempts: 1}))
+		return ParseError(resp)
+	}
+}
+
+// runChecks 并发执行所有检查，单项检查的超时时间为 Broker.TimeOut 与调用方截止时间中较早者
+func (bm *baseModule) runChecks(parent goContext.Context, checks []namedCheck) HealthReport {
+	cfg := bm.service.config().getBase()
+	ctx, cancel := goContext.WithTimeout(parent, time.Duration(cfg.Broker.TimeOut)*time.Millisecond)
+	defer cancel()
+
+	report := HealthReport{Module: cfg.module, Status: HealthUp, Checks: make([]CheckResult, len(checks))}
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
+	for _, r := range report.Checks {
+		if r.Status != HealthUp {
+			report.Status = HealthDown
+		}
+	}
+	return report
+}
+
+// runCheck 执行单项检查，捕获panic，超时未返回时按失败处理
+func runCheck(ctx goContext.Context, c namedCheck) CheckResult {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- c.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := CheckResult{Name: c.name, Status: HealthUp, Latency: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status, result.Error = HealthDown, err.Error()
+	}
+	return result
+}
+
+// readyReport 执行就绪检查，OnInit 未完成时附加 init 检查失败
+func (bm *baseModule) readyReport(ctx goContext.Context) HealthReport {
+	report := bm.runChecks(ctx, bm.reg.readyChecks)
+	if !bm.ready.Load() {
+		report.Status = HealthDown
+		report.Checks = append([]CheckResult{{Name: "init", Status: HealthDown, Error: "module not initialized"}}, report.Checks...)
+	}
+	return report
+}
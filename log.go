@@ -0,0 +1,271 @@
+package qf
+
+import (
+	goContext "context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 日志格式
+const (
+	LogFormatText = "text" // key=value 格式
+	LogFormatJson = "json" // JSON Lines，每行一个JSON
+)
+
+// 日志时间格式
+const (
+	logTimeFormat     = "2006-01-02 15:04:05.000"
+	logJsonTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// 各模块的日志，模块名 -> *slog.Logger，供 errRecover 等只知道模块名的地方使用
+var loggers = sync.Map{}
+
+// Logger 获取模块的结构化日志，输出到 Base.Log 中配置的文件、控制台和总线
+//
+//	s.Logger().Info("device online", "id", id, "ip", ip)
+//	s.Logger().Error("save failed", "error", err)
+//
+// 处理请求时使用 InfoContext 等方法并传入请求的 IContext，日志会带上 traceId 和 reqId
+//
+//	s.Logger().InfoContext(ctx, "device online", "id", id)
+func (bll *Service) Logger() *slog.Logger {
+	if bll.logger == nil {
+		return loggerOf(bll.Name())
+	}
+	return bll.logger
+}
+
+// initLogger 根据配置创建模块日志
+func (bll *Service) initLogger() {
+	cfg := bll.cfg.getBase()
+	var handlers []slog.Handler
+	if level, ok := parseLevel(cfg.Log.FileLevel); ok {
+		bll.logFile = openLogFile(cfg.Log.Dir, cfg.module, logFileOptions{
+			maxSize:  int64(cfg.Log.MaxSize) * 1024 * 1024,
+			maxAge:   time.Duration(cfg.Log.MaxAge) * 24 * time.Hour,
+			maxFiles: cfg.Log.MaxFiles,
+			compress: cfg.Log.Compress,
+		})
+		handlers = append(handlers, &flushHandler{Handler: newLogHandler(bll.logFile, level, cfg.Log.FileFormat), w: bll.logFile})
+	}
+	if level, ok := parseLevel(cfg.Log.ConsoleLevel); ok {
+		handlers = append(handlers, newLogHandler(os.Stdout, level, cfg.Log.ConsoleFormat))
+	}
+	if level, ok := parseLevel(cfg.Log.BusLevel); ok {
+		handlers = append(handlers, &busHandler{service: bll, level: level})
+	}
+	bll.logger = slog.New(&fanoutHandler{handlers: handlers}).With("module", cfg.module)
+	loggers.Store(cfg.module, bll.logger)
+	redactors.Store(cfg.module, newRedactor(cfg))
+}
+
+// loggerOf 获取模块的日志，模块未加载时只将错误写入文件
+func loggerOf(module string) *slog.Logger {
+	if l, ok := loggers.Load(module); ok {
+		return l.(*slog.Logger)
+	}
+	w := openLogFile("./log", module, logFileOptions{maxSize: 10 * 1024 * 1024, maxFiles: 20, compress: true})
+	h := &flushHandler{Handler: newLogHandler(w, slog.LevelError, LogFormatText), w: w}
+	l, _ := loggers.LoadOrStore(module, slog.New(&fanoutHandler{handlers: []slog.Handler{h}}).With("module", module))
+	return l.(*slog.Logger)
+}
+
+// closeLogger 写入缓冲并关闭日志文件，模块停止时调用
+func (bll *Service) closeLogger() {
+	if bll.logFile != nil {
+		_ = bll.logFile.Close()
+	}
+}
+
+// parseLevel 解析日志级别，NONE 或空表示不输出
+func parseLevel(s string) (slog.Level, bool) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return slog.LevelDebug, true
+	case "INFO":
+		return slog.LevelInfo, true
+	case "WARN", "WARNING":
+		return slog.LevelWarn, true
+	case "ERROR":
+		return slog.LevelError, true
+	}
+	return 0, false
+}
+
+// newLogHandler 创建日志输出，format 为 LogFormatJson 时每行一个JSON，否则为 key=value 格式
+func newLogHandler(w io.Writer, level slog.Level, format string) slog.Handler {
+	if strings.EqualFold(format, LogFormatJson) {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level:     level,
+			AddSource: true,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) > 0 {
+					return a
+				}
+				switch a.Key {
+				case slog.TimeKey:
+					return slog.String("timestamp", a.Value.Time().Format(logJsonTimeFormat))
+				case slog.SourceKey:
+					// 调用位置只保留 文件名:行号
+					if src, ok := a.Value.Any().(*slog.Source); ok {
+						return slog.String("caller", fmt.Sprintf("%s:%d", filepath.Base(src.File), src.Line))
+					}
+				}
+				return a
+			},
+		})
+	}
+	return slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.String(slog.TimeKey, a.Value.Time().Format(logTimeFormat))
+			}
+			return a
+		},
+	})
+}
+
+// logCaller 以调用方的位置记录日志，用于 SendLogDebug 等包装方法，skip 为调用方相对本方法的层数
+func logCaller(ctx goContext.Context, logger *slog.Logger, skip int, level slog.Level, msg string, args ...any) {
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = logger.Handler().Handle(ctx, r)
+}
+
+// fanoutHandler 将日志分发给多个输出，各输出按自己的级别过滤，context 中带有链路信息时附加到日志中
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+func (h *fanoutHandler) Enabled(ctx goContext.Context, level slog.Level) bool {
+	for _, hd := range h.handlers {
+		if hd.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx goContext.Context, r slog.Record) error {
+	if t := traceOf(ctx); t != nil {
+		r.AddAttrs(t.attrs()...)
+	}
+	var errs []error
+	for _, hd := range h.handlers {
+		if hd.Enabled(ctx, r.Level) {
+			if err := hd.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hs := make([]slog.Handler, len(h.handlers))
+	for i, hd := range h.handlers {
+		hs[i] = hd.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: hs}
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	hs := make([]slog.Handler, len(h.handlers))
+	for i, hd := range h.handlers {
+		hs[i] = hd.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: hs}
+}
+
+// busHandler 通过 easyCon 将日志发送到总线，Info 按 Debug 发送
+type busHandler struct {
+	service *Service
+	level   slog.Level
+	attrs   []slog.Attr
+	group   string
+}
+
+func (h *busHandler) Enabled(_ goContext.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *busHandler) Handle(_ goContext.Context, r slog.Record) error {
+	adapter := h.service.adapter
+	if adapter == nil {
+		return nil
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(r.Message)
+	var logErr error
+	write := func(a slog.Attr) bool {
+		if a.Key == "module" {
+			// 总线日志自带模块名
+			return true
+		}
+		if e, ok := a.Value.Any().(error); ok && a.Key == "error" {
+			logErr = e
+			return true
+		}
+		fmt.Fprintf(&sb, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		return write(a)
+	})
+
+	switch {
+	case r.Level >= slog.LevelError:
+		if logErr == nil {
+			logErr = errors.New("")
+		}
+		adapter.Err(sb.String(), logErr)
+	case r.Level >= slog.LevelWarn:
+		adapter.Warn(sb.String())
+	default:
+		adapter.Debug(sb.String())
+	}
+	return nil
+}
+
+func (h *busHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		nh.attrs = append(nh.attrs, a)
+	}
+	return &nh
+}
+
+func (h *busHandler) WithGroup(name string) slog.Handler {
+	nh := *h
+	if nh.group != "" {
+		name = nh.group + "." + name
+	}
+	nh.group = name
+	return &nh
+}
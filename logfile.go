@@ -0,0 +1,341 @@
+package qf
+
+import (
+	"bufio"
+	"compress/gzip"
+	goContext "context"
+	"fmt"
+	"github.com/kamioair/utils/qio"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logBufferSize    = 64 * 1024   // 日志文件的写缓冲大小
+	logFlushInterval = time.Second // 缓冲定时写入文件的间隔
+	logBackupLayout  = "20060102-150405.000"
+)
+
+// 已打开的日志文件，完整路径 -> *rotateWriter，同一文件只打开一次
+var (
+	logFiles     = map[string]*rotateWriter{}
+	logFilesLock = sync.Mutex{}
+)
+
+// logFileOptions 日志文件的滚动和保留设置
+type logFileOptions struct {
+	maxSize  int64         // 单个文件的最大字节数，0为不限制
+	maxAge   time.Duration // 历史文件的保留时长，0为不限制
+	maxFiles int           // 历史文件的保留个数，0为不限制
+	compress bool          // 是否gzip压缩历史文件
+}
+
+// rotateWriter 带缓冲的日志文件，文件保持打开，超过大小或跨天时滚动为 模块名-时间.log
+type rotateWriter struct {
+	lock      sync.Mutex
+	cleanLock sync.Mutex
+	cleaning  sync.WaitGroup // 后台压缩和清理
+	dir       string
+	module    string
+	opts      logFileOptions
+	file      *os.File
+	buf       *bufio.Writer
+	size      int64
+	day       string        // 当前文件的日期
+	stop      chan struct{} // 停止定时写入
+}
+
+// openLogFile 获取模块的日志文件，同一路径的文件共用一个 rotateWriter，设置以最后一次为准
+func openLogFile(dir, module string, opts logFileOptions) *rotateWriter {
+	dir = qio.GetFullPath(dir)
+	path := filepath.Join(dir, module+".log")
+
+	logFilesLock.Lock()
+	defer logFilesLock.Unlock()
+	w, ok := logFiles[path]
+	if !ok {
+		w = &rotateWriter{dir: dir, module: module}
+		logFiles[path] = w
+	}
+	w.lock.Lock()
+	w.opts = opts
+	w.lock.Unlock()
+	if !ok {
+		// 首次打开时按保留设置清理之前的历史文件
+		w.cleaning.Add(1)
+		go w.cleanup("", opts)
+	}
+	return w
+}
+
+func (w *rotateWriter) path() string {
+	return filepath.Join(w.dir, w.module+".log")
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	today := time.Now().Format("20060102")
+	if w.size > 0 && (w.day != today || (w.opts.maxSize > 0 && w.size+int64(len(p)) > w.opts.maxSize)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.buf.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush 将缓冲写入文件
+func (w *rotateWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.buf == nil {
+		return nil
+	}
+	return w.buf.Flush()
+}
+
+// Close 写入缓冲并关闭文件，之后再写入时重新打开
+func (w *rotateWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.close()
+}
+
+// open 打开日志文件并启动定时写入
+func (w *rotateWriter) open() error {
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file, w.buf = f, bufio.NewWriterSize(f, logBufferSize)
+	w.size, w.day = info.Size(), info.ModTime().Format("20060102")
+
+	w.stop = make(chan struct{})
+	go w.flushLoop(w.stop)
+	return nil
+}
+
+func (w *rotateWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	close(w.stop)
+	err := w.buf.Flush()
+	if e := w.file.Close(); err == nil {
+		err = e
+	}
+	w.file, w.buf = nil, nil
+	return err
+}
+
+// flushLoop 定时将缓冲写入文件
+func (w *rotateWriter) flushLoop(stop chan struct{}) {
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			_ = w.Flush()
+		}
+	}
+}
+
+// rotate 将当前文件改名为历史文件并重新打开，在后台压缩和清理历史文件
+func (w *rotateWriter) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+	backup := w.backupPath()
+	if err := os.Rename(w.path(), backup); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.cleaning.Add(1)
+	go w.cleanup(backup, w.opts)
+	return nil
+}
+
+// backupPath 历史文件的路径，同一毫秒内多次滚动时顺延时间避免覆盖
+func (w *rotateWriter) backupPath() string {
+	t := time.Now()
+	for {
+		p := filepath.Join(w.dir, fmt.Sprintf("%s-%s.log", w.module, t.Format(logBackupLayout)))
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			if _, err = os.Stat(p + ".gz"); os.IsNotExist(err) {
+				return p
+			}
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// cleanup 压缩刚滚动的历史文件，并按保留时长和个数删除旧文件，backup 为空时只清理
+func (w *rotateWriter) cleanup(backup string, opts logFileOptions) {
+	defer w.cleaning.Done()
+	w.cleanLock.Lock()
+	defer w.cleanLock.Unlock()
+
+	w.cleanLegacy(opts.maxAge)
+	if backup != "" && opts.compress {
+		if err := gzipFile(backup); err != nil {
+			fmt.Printf("compress log file [%s] failed: %v\n", backup, err)
+		}
+	}
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return
+	}
+	var backups []os.DirEntry
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".gz")
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, w.module+"-"), ".log")
+		if e.IsDir() || stamp == name || !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		if _, err := time.Parse(logBackupLayout, stamp); err == nil {
+			backups = append(backups, e)
+		}
+	}
+	// 文件名中的时间可直接排序，新的在前
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name() > backups[j].Name()
+	})
+	for i, e := range backups {
+		expired := opts.maxFiles > 0 && i >= opts.maxFiles
+		if info, err := e.Info(); err == nil && opts.maxAge > 0 && time.Since(info.ModTime()) > opts.maxAge {
+			expired = true
+		}
+		if expired {
+			_ = os.Remove(filepath.Join(w.dir, e.Name()))
+		}
+	}
+}
+
+// cleanLegacy 按保留时长删除旧版本的日志 目录/yyyy-MM/dd_模块名_级别.log，并删除清空的月份目录
+func (w *rotateWriter) cleanLegacy(maxAge time.Duration) {
+	if maxAge <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return
+	}
+	for _, month := range entries {
+		if _, err = time.Parse("2006-01", month.Name()); !month.IsDir() || err != nil {
+			continue
+		}
+		monthDir := filepath.Join(w.dir, month.Name())
+		files, err := os.ReadDir(monthDir)
+		if err != nil {
+			continue
+		}
+		left := len(files)
+		for _, f := range files {
+			if !w.isLegacyFile(f.Name()) {
+				continue
+			}
+			if info, err := f.Info(); err == nil && time.Since(info.ModTime()) > maxAge {
+				if os.Remove(filepath.Join(monthDir, f.Name())) == nil {
+					left--
+				}
+			}
+		}
+		if left == 0 {
+			_ = os.Remove(monthDir)
+		}
+	}
+}
+
+// isLegacyFile 是否为本模块旧版本的日志文件 dd_模块名_级别.log 或 dd_模块名.log
+func (w *rotateWriter) isLegacyFile(name string) bool {
+	if len(name) < 3 || name[2] != '_' || !strings.HasSuffix(name, ".log") {
+		return false
+	}
+	if _, err := strconv.Atoi(name[:2]); err != nil {
+		return false
+	}
+	rest := strings.TrimSuffix(name[3:], ".log")
+	if rest == w.module {
+		return true
+	}
+	level, ok := strings.CutPrefix(rest, w.module+"_")
+	return ok && level != "" && !strings.Contains(level, "_")
+}
+
+// gzipFile 压缩文件为 .gz 并删除原文件
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if e := zw.Close(); err == nil {
+		err = e
+	}
+	if e := dst.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// flushHandler 错误及以上级别的日志立即写入文件，避免程序异常退出时丢失
+type flushHandler struct {
+	slog.Handler
+	w *rotateWriter
+}
+
+func (h *flushHandler) Handle(ctx goContext.Context, r slog.Record) error {
+	err := h.Handler.Handle(ctx, r)
+	if r.Level >= slog.LevelError {
+		_ = h.w.Flush()
+	}
+	return err
+}
+
+func (h *flushHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &flushHandler{Handler: h.Handler.WithAttrs(attrs), w: h.w}
+}
+
+func (h *flushHandler) WithGroup(name string) slog.Handler {
+	return &flushHandler{Handler: h.Handler.WithGroup(name), w: h.w}
+}
@@ -0,0 +1,157 @@
+package qf
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// backups 获取目录下模块的历史文件名
+func backups(t *testing.T, dir, module string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), module+"-") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	w := &rotateWriter{dir: dir, module: "M", opts: logFileOptions{maxSize: 100, compress: true}}
+	line := strings.Repeat("x", 39) + "\n"
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w.cleaning.Wait()
+
+	// 每个文件最多2行，5行滚动2次
+	names := backups(t, dir, "M")
+	if len(names) != 2 {
+		t.Fatalf("backups = %v, want 2", names)
+	}
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Fatalf("backup %s not compressed", name)
+		}
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(zr)
+		_ = f.Close()
+		if err != nil || string(b) != line+line {
+			t.Fatalf("backup %s content = %q, %v", name, b, err)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "M.log"))
+	if err != nil || string(b) != line {
+		t.Fatalf("active content = %q, %v", b, err)
+	}
+}
+
+func TestRotateByDay(t *testing.T) {
+	dir := t.TempDir()
+	w := &rotateWriter{dir: dir, module: "M"}
+	if _, err := w.Write([]byte("yesterday\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.day = time.Now().AddDate(0, 0, -1).Format("20060102")
+	if _, err := w.Write([]byte("today\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	w.cleaning.Wait()
+
+	names := backups(t, dir, "M")
+	if len(names) != 1 || !strings.HasSuffix(names[0], ".log") {
+		t.Fatalf("backups = %v, want 1 uncompressed", names)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, names[0]))
+	if string(b) != "yesterday\n" {
+		t.Fatalf("backup content = %q", b)
+	}
+}
+
+func TestRotateMaxFiles(t *testing.T) {
+	dir := t.TempDir()
+	w := &rotateWriter{dir: dir, module: "M", opts: logFileOptions{maxSize: 10, maxFiles: 3}}
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = w.Close()
+	w.cleaning.Wait()
+
+	if names := backups(t, dir, "M"); len(names) != 3 {
+		t.Fatalf("backups = %v, want 3", names)
+	}
+}
+
+func TestCleanupMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	files := []struct {
+		name string
+		old  bool // 修改时间是否超过保留时长
+		keep bool // 清理后是否应保留
+	}{
+		{"M-20200101-000000.000.log.gz", true, false},
+		{"M-20200102-000000.000.log", true, false},
+		{"M-20200103-000000.000.log", false, true},
+		{"N-20200101-000000.000.log", true, true}, // 其他模块
+		{"M-notes.log", true, true},               // 不是历史文件
+		{"2020-01/05_M_ERROR.log", true, false},
+		{"2020-01/05_M.log", true, false},
+		{"2020-01/05_M_B_ERROR.log", true, true}, // 模块 M_B
+		{"2020-02/06_M_WARN.log", true, false},
+		{"2020-03/07_M_ERROR.log", false, true},
+		{"other/05_M_ERROR.log", true, true}, // 不是月份目录
+	}
+	for _, f := range files {
+		p := filepath.Join(dir, f.name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if f.old {
+			_ = os.Chtimes(p, old, old)
+		}
+	}
+
+	w := &rotateWriter{dir: dir, module: "M"}
+	w.cleaning.Add(1)
+	w.cleanup("", logFileOptions{maxAge: 24 * time.Hour})
+
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(dir, f.name))
+		if exists := err == nil; exists != f.keep {
+			t.Errorf("%s exists = %v, want %v", f.name, exists, f.keep)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2020-02")); !os.IsNotExist(err) {
+		t.Errorf("empty month dir not removed")
+	}
+}
@@ -20,6 +20,7 @@ static int OnWriteHandler(OnWriteCallback cb, char* respBytes, int respLen, char
 import "C"
 
 import (
+	goContext "context"
 	"fmt"
 	easyCon "github.com/qiu-tec/easy-con.golang"
 	"syscall"
@@ -75,7 +76,7 @@ func (p *plugin) Name() string {
 func (p *plugin) Run() {
 	cfg := p.service.config().getBase()
 
-	defer errRecover(func(err string) {
+	defer errRecover(goContext.Background(), func(err string) {
 		fmt.Println("")
 		fmt.Println(err)
 		fmt.Println("-------------------------------------")
@@ -1,6 +1,7 @@
 package qf
 
 import (
+	goContext "context"
 	"fmt"
 	"github.com/kamioair/utils/qconvert"
 	"github.com/kamioair/utils/qlauncher"
@@ -54,7 +55,7 @@ func (m *module) Stop() {
 func (m *module) start() {
 	cfg := m.service.config().getBase()
 
-	defer errRecover(func(err string) {
+	defer errRecover(goContext.Background(), func(err string) {
 		fmt.Println("")
 		fmt.Println(err)
 		fmt.Println("-------------------------------------")
@@ -62,6 +63,7 @@ func (m *module) start() {
 
 	m.waitConnectChan = make(chan bool)
 	m.waitLock = sync.Mutex{}
+	m.resetCtx()
 
 	// 打印模块信息
 	m.printModuleInfo()
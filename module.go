@@ -1,10 +1,15 @@
 package qf
 
 import (
+	goContext "context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	easyCon "github.com/qiu-tec/easy-con.golang"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // baseModule 基础模块，包含所有模块类型的公共实现
@@ -12,8 +17,15 @@ type baseModule struct {
 	service IService
 	reg     *Reg
 	adapter easyCon.IAdapter
+	ctx     goContext.Context // 模块运行期间的上下文，停止时取消
+	cancel  goContext.CancelFunc
+	ready   atomic.Bool  // OnInit 是否已执行完成
+	stats   *moduleStats // 运行统计
 }
 
+// 正在处理中的请求上下文，供 OnReq 中调用的 Invoke 获取
+var reqContexts = sync.Map{}
+
 // newBaseModule 创建基础模块
 func newBaseModule(service IService) *baseModule {
 	if service == nil {
@@ -25,10 +37,17 @@ func newBaseModule(service IService) *baseModule {
 		reg:     &Reg{},
 	}
 	bm.service.Reg(bm.reg)
+	bm.resetCtx()
 
 	return bm
 }
 
+// resetCtx 重建模块上下文和运行统计
+func (bm *baseModule) resetCtx() {
+	bm.ctx, bm.cancel = goContext.WithCancel(goContext.Background())
+	bm.stats = newModuleStats()
+}
+
 // getService 获取服务接口
 func (bm *baseModule) getService() IService {
 	return bm.service
@@ -74,11 +93,17 @@ func (bm *baseModule) buildAdapterCallBack(
 		OnExiting:       onExiting,
 		OnGetVersion:    onGetVersion,
 	}
-	if bm.reg.OnNotice != nil {
-		callback.OnNoticeRec = bm.reg.OnNotice
+	// 有订阅时同样需要接收通知，由 onNotice 统一分发
+	hasNotice, hasRetain := bm.reg.OnNotice != nil, bm.reg.OnRetainNotice != nil
+	for _, s := range bm.reg.subscribers {
+		hasNotice = hasNotice || !s.retain
+		hasRetain = hasRetain || s.retain
+	}
+	if hasNotice {
+		callback.OnNoticeRec = bm.onNotice
 	}
-	if bm.reg.OnRetainNotice != nil {
-		callback.OnRetainNoticeRec = bm.reg.OnRetainNotice
+	if hasRetain {
+		callback.OnRetainNoticeRec = bm.onRetainNotice
 	}
 	if bm.reg.OnLog != nil {
 		callback.OnLogRec = bm.reg.OnLog
@@ -88,6 +113,7 @@ func (bm *baseModule) buildAdapterCallBack(
 
 func (bm *baseModule) callOnState(status easyCon.EStatus) {
 	fmt.Printf("Link state = [%s]\n", status)
+	bm.stats.recordLink(status)
 	if bm.reg != nil && bm.reg.OnStatusChanged != nil {
 		go bm.reg.OnStatusChanged(status)
 	}
@@ -95,17 +121,22 @@ func (bm *baseModule) callOnState(status easyCon.EStatus) {
 
 // callOnInit 调用业务初始化回调
 func (bm *baseModule) callOnInit() {
-	bm.service.setEnv(bm.reg, bm.adapter)
+	bm.service.setEnv(bm.reg, bm.adapter, bm.ctx)
+	bm.subscribeNotices()
 	if bm.reg.OnInit != nil {
 		bm.reg.OnInit()
 	}
+	bm.ready.Store(true)
 }
 
-// callOnStop 调用业务停止回调
+// callOnStop 调用业务停止回调，并取消所有处理中的请求
 func (bm *baseModule) callOnStop() {
+	bm.ready.Store(false)
 	if bm.reg.OnStop != nil {
 		bm.reg.OnStop()
 	}
+	bm.cancel()
+	bm.service.closeLogger()
 }
 
 // decryptBrokerConfig 解密 Broker 配置
@@ -138,17 +169,85 @@ func (bm *baseModule) decryptBrokerConfig() (addr, uid, pwd string) {
 func (bm *baseModule) handleReq(pack easyCon.PackReq, onStop func()) (code easyCon.EResp, resp []byte) {
 	cfg := bm.service.config().getBase()
 
-	defer errRecover(func(err string) {
+	// 拆分请求头，按调用方的超时时间设置截止时间，按调用方的编解码解析内容
+	// 沿用调用方的链路Id，调用方未传递时生成新的，处理期间的日志均带有链路Id和请求Id
+	header, content := unpackEnvelope(pack.Content)
+	pack.Content = content
+	if header.TraceId == "" {
+		header.TraceId = newTraceId()
+	}
+	parent, cancel := withTrace(bm.ctx, header.TraceId, pack.Id), goContext.CancelFunc(func() {})
+	if header.Timeout > 0 {
+		parent, cancel = goContext.WithTimeout(parent, time.Duration(header.Timeout)*time.Millisecond)
+	}
+	defer cancel()
+
+	// 只有分发到业务路由的请求计入统计，内置路由、未匹配的路由以及被中间件拦截的请求均不计入，避免任意路由名撑大统计
+	var start time.Time
+	var inType reflect.Type
+	m, matched := bm.reg.routes[pack.Route], false
+	if m != nil {
+		inType = m.inType
+	}
+	defer errRecover(parent, func(err string) {
 		code = easyCon.ERespError
 		resp = []byte(err)
-	}, cfg.module, pack.Route, pack.Content)
+		// 业务panic时同样计入统计
+		if matched {
+			bm.stats.recordReq(pack.Route, code, time.Since(start))
+		}
+	}, cfg.module, pack.Route, logParam{content: pack.Content, typ: inType})
+
+	ctx := newReqContext(parent, &pack)
+	codec, err := getCodec(header.Codec)
+	if err != nil {
+		return easyCon.ERespBadReq, []byte(err.Error())
+	}
+	ctx.codec = withStrict(codec, cfg.Request.StrictJson)
+	ctx.meta = header.Meta
+	key := reqKey(&pack)
+	reqContexts.Store(key, ctx)
+	defer reqContexts.Delete(key)
+
+	// 内置路由和业务路由都经过中间件
+	handler := func(ctx IContext) (easyCon.EResp, []byte) {
+		if c, r, ok := bm.builtin(ctx, pack, onStop); ok {
+			return c, r
+		}
+		// 注册的路由在调用前即视为匹配，业务panic时同样计入统计
+		matched = m != nil
+		c, r, ok := bm.dispatch(ctx, pack)
+		matched = ok
+		return c, r
+	}
+	for i := len(bm.reg.middlewares) - 1; i >= 0; i-- {
+		handler = bm.reg.middlewares[i](handler)
+	}
+	start = time.Now()
+	code, resp = handler(ctx)
+	if matched {
+		bm.stats.recordReq(pack.Route, code, time.Since(start))
+	}
+	if code != easyCon.ERespSuccess {
+		// 记录日志
+		loggerOf(cfg.module).ErrorContext(ctx, "OnReq failed", "from", pack.From, "route", pack.Route, "code", int(code), "inParam", logPayload(cfg.module, pack.Content, inType), "error", string(resp))
+	}
+	// 调用方已超时放弃等待，不再回复
+	if parent.Err() == goContext.DeadlineExceeded {
+		return easyCon.ERespBypass, nil
+	}
+	return code, resp
+}
 
+// builtin 处理框架内置路由，返回是否为内置路由
+func (bm *baseModule) builtin(ctx IContext, pack easyCon.PackReq, onStop func()) (easyCon.EResp, []byte, bool) {
+	cfg := bm.service.config().getBase()
 	switch pack.Route {
 	case "Exit":
 		if onStop != nil {
 			onStop()
 		}
-		return easyCon.ERespSuccess, nil
+		return easyCon.ERespSuccess, nil, true
 	case "Version":
 		ver := map[string]string{}
 		ver["Module"] = cfg.module
@@ -156,20 +255,51 @@ func (bm *baseModule) handleReq(pack easyCon.PackReq, onStop func()) (code easyC
 		ver["ModuleVersion"] = cfg.version
 		ver["FrameVersion"] = Version
 		j, _ := json.Marshal(ver)
-		return easyCon.ERespSuccess, j
+		return easyCon.ERespSuccess, j, true
+	case "Breakers":
+		j, _ := json.Marshal(bm.service.breakerStates())
+		return easyCon.ERespSuccess, j, true
+	case "Describe":
+		j, _ := json.Marshal(describe(bm.reg, cfg))
+		return easyCon.ERespSuccess, j, true
+	case "Health":
+		j, _ := json.Marshal(bm.runChecks(ctx, bm.reg.healthChecks))
+		return easyCon.ERespSuccess, j, true
+	case "Ready":
+		j, _ := json.Marshal(bm.readyReport(ctx))
+		return easyCon.ERespSuccess, j, true
+	case "Stats":
+		j, _ := json.Marshal(bm.stats.snapshot(cfg.module))
+		return easyCon.ERespSuccess, j, true
 	}
+	return 0, nil, false
+}
 
+// dispatch 分发请求，优先匹配注册的路由，未匹配时交给 OnReq 处理，返回是否匹配到路由
+// OnReq 返回非结构化的 RouteNotFind 视为未匹配，业务返回的 qf.NotFound 为结构化错误
+func (bm *baseModule) dispatch(ctx IContext, pack easyCon.PackReq) (easyCon.EResp, []byte, bool) {
+	if m, ok := bm.reg.routes[pack.Route]; ok {
+		code, resp := m.call(ctx, pack)
+		return code, resp, true
+	}
 	if bm.reg.OnReq != nil {
-		code, resp = bm.reg.OnReq(pack)
-		if code != easyCon.ERespSuccess {
-			// 记录日志
-			str, _ := json.Marshal(pack.Content)
-			errStr := string(resp)
-			writeLog(cfg.module, "Error", fmt.Sprintf("[OnReq From %s.%s] InParam=%s", pack.From, pack.Route, str), formatRespError(code, errStr))
-		}
-		return code, resp
+		code, resp := bm.reg.OnReq(pack)
+		return code, resp, code != easyCon.ERespRouteNotFind || parseErrorContent(code, resp) != nil
+	}
+	return easyCon.ERespRouteNotFind, []byte("Route Not Matched"), false
+}
+
+// reqKey 请求的唯一标识
+func reqKey(pack *easyCon.PackReq) string {
+	return fmt.Sprintf("%s>%s#%d", pack.From, pack.To, pack.Id)
+}
+
+// lookupReqCtx 获取处理中的请求上下文，不存在时创建无截止时间的上下文
+func lookupReqCtx(pack *easyCon.PackReq) IContext {
+	if ctx, ok := reqContexts.Load(reqKey(pack)); ok {
+		return ctx.(IContext)
 	}
-	return easyCon.ERespRouteNotFind, []byte("Route Not Matched")
+	return newReqContext(goContext.Background(), pack)
 }
 
 // getVersion 获取版本信息
@@ -0,0 +1,168 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"reflect"
+	"strings"
+)
+
+// subscriber 通知订阅
+type subscriber struct {
+	pattern string
+	parts   []string     // 按 / 拆分的匹配规则
+	retain  bool         // 是否为保留通知
+	msgType reflect.Type // 通知内容类型
+	handle  func(ctx *context) error
+}
+
+// Subscribe 订阅通知，通知内容按 T 类型解析后调用 fn
+// pattern 与 MQTT 主题规则一致，用 / 分级，+ 匹配一级，# 匹配剩余所有级（只能放在最后）
+// 例如 Device/+/State 匹配 Device/A/State，Device/# 匹配 Device 下的所有通知
+// 内容的解析方式与 Invoke 一致，T 的 qf 标签同样生效，解析或校验失败时记录日志并跳过该订阅
+// 每个订阅独立捕获panic，不影响其他订阅和 OnNotice
+func Subscribe[T any](reg *Reg, pattern string, fn func(ctx IContext, msg T)) {
+	reg.subscribe(pattern, false, reflect.TypeOf((*T)(nil)).Elem(), noticeHandler(pattern, fn))
+}
+
+// SubscribeRetain 订阅保留通知，规则同 Subscribe
+// 保留通知被清除时内容为空，T 不是 string 或 []byte 时以零值调用 fn
+func SubscribeRetain[T any](reg *Reg, pattern string, fn func(ctx IContext, msg T)) {
+	reg.subscribe(pattern, true, reflect.TypeOf((*T)(nil)).Elem(), noticeHandler(pattern, fn))
+}
+
+// noticeHandler 构建解析通知内容并调用 fn 的方法，T 的校验规则不合法时直接panic
+func noticeHandler[T any](pattern string, fn func(ctx IContext, msg T)) func(ctx *context) error {
+	if fn == nil {
+		panic(fmt.Errorf("subscribe [%s] handler is nil", pattern))
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	rules, err := getValidator(t)
+	if err != nil {
+		panic(fmt.Errorf("subscribe [%s] %v", pattern, err))
+	}
+	return func(ctx *context) error {
+		// 清除保留通知时内容为空，按零值传给订阅
+		if ctx.raw == "" && !isRawType(t) {
+			var zero T
+			fn(ctx, zero)
+			return nil
+		}
+		msg, err := decodeContent(t, []byte(ctx.raw), ctx.payloadCodec())
+		if err != nil {
+			return err
+		}
+		if err = validateValue(rules, msg); err != nil {
+			return err
+		}
+		fn(ctx, msg.Interface().(T))
+		return nil
+	}
+}
+
+// subscribe 注册订阅，规则不合法时直接panic
+func (reg *Reg) subscribe(pattern string, retain bool, msgType reflect.Type, handle func(ctx *context) error) {
+	parts := strings.Split(pattern, "/")
+	for i, p := range parts {
+		if p == "#" && i != len(parts)-1 {
+			panic(fmt.Errorf("subscribe [%s] # must be the last level", pattern))
+		}
+		if p != "#" && p != "+" && strings.ContainsAny(p, "#+") {
+			panic(fmt.Errorf("subscribe [%s] wildcard must occupy an entire level", pattern))
+		}
+	}
+	reg.subscribers = append(reg.subscribers, &subscriber{pattern: pattern, parts: parts, retain: retain, msgType: msgType, handle: handle})
+}
+
+// match 通知路由是否匹配订阅规则
+func (s *subscriber) match(route string) bool {
+	levels := strings.Split(route, "/")
+	for i, p := range s.parts {
+		if p == "#" {
+			return true
+		}
+		if i >= len(levels) || (p != "+" && p != levels[i]) {
+			return false
+		}
+	}
+	return len(levels) == len(s.parts)
+}
+
+// topic 向 Broker 订阅的主题，+ 及之后的部分用 # 代替，由本地再精确匹配
+func (s *subscriber) topic() string {
+	for i, p := range s.parts {
+		if p == "+" || p == "#" {
+			return strings.Join(append(s.parts[:i:i], "#"), "/")
+		}
+	}
+	return s.pattern
+}
+
+// subscribeNotices 向 Broker 订阅所有已注册的通知，断线重连后由适配器自动重新订阅
+func (bm *baseModule) subscribeNotices() {
+	topics := map[string]bool{}
+	for _, s := range bm.reg.subscribers {
+		key := fmt.Sprintf("%v:%s", s.retain, s.topic())
+		if topics[key] {
+			continue
+		}
+		topics[key] = true
+		bm.adapter.SubscribeNotice(s.topic(), s.retain)
+	}
+}
+
+// onNotice 分发通知，先调用 OnNotice，再调用所有匹配的订阅
+func (bm *baseModule) onNotice(pack easyCon.PackNotice) {
+	if bm.reg.OnNotice != nil {
+		bm.reg.OnNotice(pack)
+	}
+	bm.dispatchNotice(pack, false)
+}
+
+// onRetainNotice 分发保留通知，先调用 OnRetainNotice，再调用所有匹配的订阅
+func (bm *baseModule) onRetainNotice(pack easyCon.PackNotice) {
+	if bm.reg.OnRetainNotice != nil {
+		bm.reg.OnRetainNotice(pack)
+	}
+	bm.dispatchNotice(pack, true)
+}
+
+// dispatchNotice 按顺序调用匹配的订阅
+func (bm *baseModule) dispatchNotice(pack easyCon.PackNotice, retain bool) {
+	for _, s := range bm.reg.subscribers {
+		if s.retain == retain && s.match(pack.Route) {
+			bm.callSubscriber(s, pack)
+		}
+	}
+}
+
+// newNoticeContext 创建通知上下文，通知带有编解码标识时按其解析内容
+func newNoticeContext(parent goContext.Context, pack easyCon.PackNotice, strict bool) (*context, error) {
+	header, content := unpackEnvelope(pack.Content)
+	pack.Content = content
+	codec, err := getCodec(header.Codec)
+	if err != nil {
+		return nil, err
+	}
+	return &context{
+		Context:    parent,
+		raw:        string(content),
+		noticePack: &pack,
+		codec:      withStrict(codec, strict),
+	}, nil
+}
+
+// callSubscriber 调用单个订阅，panic和错误只记录日志
+func (bm *baseModule) callSubscriber(s *subscriber, pack easyCon.PackNotice) {
+	cfg := bm.service.config().getBase()
+	defer errRecover(bm.ctx, nil, cfg.module, pack.Route, logParam{content: pack.Content, typ: s.msgType})
+
+	ctx, err := newNoticeContext(bm.ctx, pack, cfg.Request.StrictJson)
+	if err == nil {
+		err = s.handle(ctx)
+	}
+	if err != nil {
+		loggerOf(cfg.module).Error("Subscribe failed", "pattern", s.pattern, "from", pack.From, "route", pack.Route, "inParam", logPayload(cfg.module, pack.Content, s.msgType), "error", err)
+	}
+}
@@ -0,0 +1,158 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"reflect"
+	"time"
+)
+
+// EOutKind 出站消息类型
+type EOutKind string
+
+const (
+	EOutKindRequest           EOutKind = "Request"
+	EOutKindNotice            EOutKind = "Notice"
+	EOutKindRetainNotice      EOutKind = "RetainNotice"
+	EOutKindClearRetainNotice EOutKind = "ClearRetainNotice"
+)
+
+// Outbound 出站消息，拦截器可修改其中的内容
+type Outbound struct {
+	Kind    EOutKind          // 消息类型
+	Module  string            // 目标模块，通知时为空
+	Route   string            // 路由
+	Content []byte            // 内容
+	Timeout int               // 请求超时时间（毫秒），0为使用 Broker.TimeOut
+	Codec   string            // 请求或通知内容的编解码，为空时为JSON
+	Meta    map[string]string // 随请求传递的元数据，被调用方通过 IContext.Meta 获取，仅请求有效，需启用 Base.Request.Header
+	TraceId string            // 链路Id，为空时自动生成，被调用方通过 IContext.TraceId 获取，仅请求有效，需启用 Base.Request.Header
+
+	Retry      *RetryPolicy      // 重试策略，为nil时使用 Base.Request 配置，仅请求有效
+	Idempotent bool              // 是否幂等，幂等的请求才会在超时后重试
+	Ctx        goContext.Context // 发起请求的上下文，结束后不再重试，为nil时只在模块停止后不再重试，仅请求有效
+
+	contentType reflect.Type // 内容编码前的类型，用于日志脱敏，直接发送字节时为nil
+}
+
+// Sender 出站发送方法，通知发送失败时返回 ERespError
+type Sender func(out *Outbound) easyCon.PackResp
+
+// Interceptor 出站拦截器，可在调用 next 前后加入链路追踪、重试、熔断、脱敏、统计等通用逻辑
+type Interceptor func(next Sender) Sender
+
+// Intercept 注册出站拦截器，作用于 Service 发出的所有请求和通知，先注册的在外层
+func (reg *Reg) Intercept(interceptor ...Interceptor) {
+	reg.interceptors = append(reg.interceptors, interceptor...)
+}
+
+// sendOut 经过拦截器和熔断器后发送出站消息，请求按重试策略重试，最终失败时记录日志
+// 熔断器打开时直接失败，只在打开时记录一次日志
+func (bll *Service) sendOut(out *Outbound, opts ...CallOption) easyCon.PackResp {
+	for _, opt := range opts {
+		opt(out)
+	}
+	sender := bll.breaker(bll.send)
+	if bll.reg != nil {
+		for i := len(bll.reg.interceptors) - 1; i >= 0; i-- {
+			sender = bll.reg.interceptors[i](sender)
+		}
+	}
+
+	var resp easyCon.PackResp
+	if out.Kind == EOutKindRequest {
+		if out.TraceId == "" {
+			out.TraceId = newTraceId()
+		}
+		policy := bll.retryPolicy(out)
+		idempotent := bll.isIdempotent(out)
+		for attempt := 1; ; attempt++ {
+			resp = sender(out)
+			if attempt >= policy.MaxAttempts || !policy.retryable(resp.RespCode, idempotent) {
+				break
+			}
+			if !bll.waitRetry(out.Ctx, policy.delay(attempt)) {
+				break
+			}
+		}
+	} else {
+		resp = sender(out)
+	}
+
+	if resp.RespCode != easyCon.ERespSuccess && resp.RespCode != ERespBreakerOpen {
+		// 记录日志
+		str := logPayload(bll.Name(), out.Content, out.contentType)
+		switch out.Kind {
+		case EOutKindRequest:
+			name := "SendRequest"
+			if out.Timeout > 0 {
+				name = "SendRequestWithTimeout"
+			}
+			bll.Logger().Error(name+" failed", "to", out.Module, "route", out.Route, "code", int(resp.RespCode), "inParam", str, "error", string(resp.Content),
+				"traceId", out.TraceId, "reqId", resp.Id)
+		default:
+			bll.Logger().Error("Send"+string(out.Kind)+" failed", "route", out.Route, "inParam", str, "error", string(resp.Content))
+		}
+	}
+	return resp
+}
+
+// waitRetry 等待重试，ctx 结束或模块停止时返回false
+func (bll *Service) waitRetry(ctx goContext.Context, d time.Duration) bool {
+	if ctxDone(ctx) || ctxDone(bll.ctx) {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-doneOf(ctx):
+	case <-doneOf(bll.ctx):
+	}
+	return false
+}
+
+// doneOf 获取 ctx 的结束通道，ctx 为nil时返回永不关闭的通道
+func doneOf(ctx goContext.Context) <-chan struct{} {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Done()
+}
+
+// ctxDone ctx 是否已结束
+func ctxDone(ctx goContext.Context) bool {
+	return ctx != nil && ctx.Err() != nil
+}
+
+// send 实际发送出站消息
+func (bll *Service) send(out *Outbound) easyCon.PackResp {
+	var err error
+	switch out.Kind {
+	case EOutKindRequest:
+		// 请求头需被调用方支持，仅在启用 Base.Request.Header 或使用非JSON编解码时发送
+		content := out.Content
+		if bll.cfg.getBase().Request.Header || (out.Codec != "" && out.Codec != CodecJson) {
+			header := reqHeader{Timeout: out.Timeout, Codec: out.Codec, Meta: out.Meta, TraceId: out.TraceId}
+			content = packEnvelope(header, out.Content)
+		}
+		if out.Timeout > 0 {
+			return bll.adapter.ReqWithTimeout(out.Module, out.Route, content, out.Timeout)
+		}
+		return bll.adapter.Req(out.Module, out.Route, content)
+	case EOutKindNotice:
+		err = bll.adapter.SendNotice(out.Route, packNoticeCodec(out))
+	case EOutKindRetainNotice:
+		err = bll.adapter.SendRetainNotice(out.Route, packNoticeCodec(out))
+	case EOutKindClearRetainNotice:
+		err = bll.adapter.CleanRetainNotice(out.Route)
+	default:
+		err = fmt.Errorf("unknown outbound kind [%s]", out.Kind)
+	}
+	if err != nil {
+		return easyCon.PackResp{RespCode: easyCon.ERespError, PackReq: easyCon.PackReq{Content: []byte(err.Error())}}
+	}
+	return easyCon.PackResp{RespCode: easyCon.ERespSuccess}
+}
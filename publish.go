@@ -0,0 +1,29 @@
+package qf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Publish 发送通知，value 的编码方式与 Invoke 的返回值一致：string 和 []byte 原样发送，其他类型使用 Base.Request.Codec 配置的编解码
+// 非JSON编解码时通知内容前带有编解码标识，接收方需使用 Subscribe 或 NoticeInvoke 按同一类型解析
+func Publish[T any](s *Service, route string, value T) error {
+	return publish(s, EOutKindNotice, route, value)
+}
+
+// PublishRetain 发送保留通知，编码方式同 Publish，新订阅的模块也会收到最后一次的内容
+func PublishRetain[T any](s *Service, route string, value T) error {
+	return publish(s, EOutKindRetainNotice, route, value)
+}
+
+func publish(s *Service, kind EOutKind, route string, value any) error {
+	codec, err := getCodec(s.cfg.getBase().Request.Codec)
+	if err != nil {
+		return fmt.Errorf("[Publish %s] %v", route, err)
+	}
+	content, err := encodeContent(value, codec)
+	if err != nil {
+		return fmt.Errorf("[Publish %s] marshal notice failed: %v", route, err)
+	}
+	return ParseError(s.sendOut(&Outbound{Kind: kind, Route: route, Content: content, Codec: codec.Name(), contentType: reflect.TypeOf(value)}))
+}
@@ -0,0 +1,196 @@
+package qf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 脱敏后的内容
+const redactMask = "***"
+
+// 已解析的类型中带 qf:"secret" 标签的字段路径，reflect.Type -> map[string]bool
+var secretPaths = sync.Map{}
+
+// 各模块的脱敏规则，模块名 -> *redactor
+var redactors = sync.Map{}
+
+// redactor 日志中请求参数等内容的脱敏规则
+type redactor struct {
+	fields   map[string]bool // 按名称匹配的字段（小写）
+	paths    map[string]bool // 按路径匹配的字段（小写），如 user.password
+	patterns []*regexp.Regexp
+	maxSize  int // 最大长度（字节），0为不限制
+}
+
+// newRedactor 根据配置创建脱敏规则
+func newRedactor(cfg *Config) *redactor {
+	r := &redactor{fields: map[string]bool{}, paths: map[string]bool{}, maxSize: cfg.Log.MaxPayload}
+	for _, f := range cfg.Log.RedactFields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch {
+		case f == "":
+		case strings.Contains(f, "."):
+			r.paths[f] = true
+		default:
+			r.fields[f] = true
+		}
+	}
+	for _, p := range cfg.Log.RedactPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			panic(fmt.Sprintf("invalid log redact pattern [%s]: %v", p, err))
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r
+}
+
+// logParam 待记录的参数内容及其类型，用于 errRecover
+type logParam struct {
+	content []byte
+	typ     reflect.Type
+}
+
+// logPayload 将请求参数等内容转为日志文本，按模块的规则脱敏并限制长度
+// t 为内容对应的类型，其中带 qf:"secret" 标签的字段按路径脱敏，类型未知时传nil，只按配置脱敏
+func logPayload(module string, content []byte, t reflect.Type) string {
+	r, ok := redactors.Load(module)
+	if !ok {
+		// 模块未加载时只处理 secret 字段和长度
+		r = &redactor{maxSize: 2048}
+	}
+	return r.(*redactor).apply(content, secretsOf(t))
+}
+
+// apply 脱敏并限制长度，JSON 按字段脱敏，非UTF-8的二进制内容只记录长度，secrets 为需脱敏的字段路径
+func (r *redactor) apply(content []byte, secrets map[string]bool) string {
+	if len(content) == 0 {
+		return ""
+	}
+	var text string
+	switch {
+	case json.Valid(content):
+		text = string(content)
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.UseNumber()
+		if dec.Decode(&v) == nil && r.redact(v, "", secrets) {
+			text = marshalLog(v)
+		}
+	case !utf8.Valid(content):
+		return fmt.Sprintf("<binary %d bytes>", len(content))
+	default:
+		text = string(content)
+	}
+	for _, re := range r.patterns {
+		text = re.ReplaceAllString(text, redactMask)
+	}
+	if r.maxSize > 0 && len(text) > r.maxSize {
+		n := r.maxSize
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		text = fmt.Sprintf("%s...(%d bytes)", text[:n], len(text))
+	}
+	return text
+}
+
+// redact 替换需脱敏字段的值，path 为当前值的路径（小写），数组元素不计入路径，返回是否有替换
+func (r *redactor) redact(v any, path string, secrets map[string]bool) bool {
+	changed := false
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			p := strings.ToLower(k)
+			if path != "" {
+				p = path + "." + p
+			}
+			if r.fields[strings.ToLower(k)] || r.paths[p] || secrets[p] {
+				val[k] = redactMask
+				changed = true
+				continue
+			}
+			if r.redact(item, p, secrets) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if r.redact(item, path, secrets) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// marshalLog 将脱敏后的值转回JSON，不转义HTML字符
+func marshalLog(v any) string {
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return redactMask
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// secretsOf 获取类型中带 qf:"secret" 标签的字段路径（小写，与 redact 的路径一致），t 为nil时返回nil
+func secretsOf(t reflect.Type) map[string]bool {
+	if t == nil {
+		return nil
+	}
+	if v, ok := secretPaths.Load(t); ok {
+		return v.(map[string]bool)
+	}
+	paths := map[string]bool{}
+	collectSecrets(t, "", paths, map[reflect.Type]bool{})
+	secretPaths.Store(t, paths)
+	return paths
+}
+
+// collectSecrets 收集结构体中的 secret 字段路径，visiting 用于处理自引用的结构体
+func collectSecrets(t reflect.Type, prefix string, paths map[string]bool, visiting map[reflect.Type]bool) {
+	t = elemStructType(t)
+	if t == nil || visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		// 匿名嵌入的结构体字段在 JSON 中展开
+		if f.Anonymous && tag == "" && elemStructType(f.Type) != nil {
+			collectSecrets(f.Type, prefix, paths, visiting)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		p := strings.ToLower(jsonFieldName(f))
+		if prefix != "" {
+			p = prefix + "." + p
+		}
+		rules, _ := parseRules(f)
+		secret := false
+		for _, rl := range rules {
+			secret = secret || rl.name == "secret"
+		}
+		if secret {
+			paths[p] = true
+			continue
+		}
+		collectSecrets(f.Type, p, paths, visiting)
+	}
+}
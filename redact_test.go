@@ -0,0 +1,117 @@
+package qf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type redactInner struct {
+	Key  string `qf:"secret"`
+	Name string
+}
+
+type redactBase struct {
+	Pin string `qf:"required,secret"`
+}
+
+type redactReq struct {
+	redactBase
+	Name  string
+	Card  string `json:"cardNo" qf:"secret"`
+	Inner redactInner
+	Items []*redactInner
+	Ptr   *redactInner `json:"ptr,omitempty"`
+	Skip  string       `json:"-" qf:"secret"`
+}
+
+type redactNode struct {
+	Token string `qf:"secret"`
+	Next  *redactNode
+}
+
+// newTestRedactor 按配置创建脱敏规则
+func newTestRedactor(fields, patterns []string, maxSize int) *redactor {
+	cfg := &Config{}
+	cfg.Log.RedactFields = fields
+	cfg.Log.RedactPatterns = patterns
+	cfg.Log.MaxPayload = maxSize
+	return newRedactor(cfg)
+}
+
+func TestRedactApply(t *testing.T) {
+	reqType := reflect.TypeOf(redactReq{})
+	tests := []struct {
+		name     string
+		fields   []string
+		patterns []string
+		maxSize  int
+		typ      reflect.Type
+		in       string
+		want     string
+	}{
+		{name: "empty", in: "", want: ""},
+		{name: "unchanged keeps original text", fields: []string{"password"}, in: `{"n": 1.50, "s":"a<b>"}`, want: `{"n": 1.50, "s":"a<b>"}`},
+		{name: "name ignores case", fields: []string{"password"}, in: `{"PassWord":"p","user":"u"}`, want: `{"PassWord":"***","user":"u"}`},
+		{name: "config name ignores case", fields: []string{"Token"}, in: `{"token":"t"}`, want: `{"token":"***"}`},
+		{name: "name at any depth", fields: []string{"token"}, in: `{"a":{"b":{"token":1}},"list":[{"token":[1,2]}]}`, want: `{"a":{"b":{"token":"***"}},"list":[{"token":"***"}]}`},
+		{name: "path from root", fields: []string{"profile.phone"}, in: `{"phone":"1","profile":{"phone":"2","name":"n"}}`, want: `{"phone":"1","profile":{"name":"n","phone":"***"}}`},
+		{name: "path ignores case", fields: []string{"Profile.Phone"}, in: `{"PROFILE":{"pHone":"2"}}`, want: `{"PROFILE":{"pHone":"***"}}`},
+		{name: "path skips array index", fields: []string{"profile.phone"}, in: `{"profile":[{"phone":"1"},{"phone":"2"}]}`, want: `{"profile":[{"phone":"***"},{"phone":"***"}]}`},
+		{name: "keeps big numbers and html", fields: []string{"password"}, in: `{"password":"p","n":12345678901234567890,"s":"<a&b>"}`, want: `{"n":12345678901234567890,"password":"***","s":"<a&b>"}`},
+		{name: "secret by type", typ: reqType, in: `{"Pin":"1","Name":"n","cardNo":"2","Inner":{"Key":"3","Name":"n"},"Items":[{"Key":"4","Name":"n"}],"ptr":{"Key":"5"}}`,
+			want: `{"Inner":{"Key":"***","Name":"n"},"Items":[{"Key":"***","Name":"n"}],"Name":"n","Pin":"***","cardNo":"***","ptr":{"Key":"***"}}`},
+		{name: "secret path ignores case", typ: reqType, in: `{"CARDNO":"2","inner":{"key":"3"}}`, want: `{"CARDNO":"***","inner":{"key":"***"}}`},
+		{name: "secret only at its path", typ: reqType, in: `{"Key":"1","Inner":{"cardNo":"2"},"Skip":"3"}`, want: `{"Key":"1","Inner":{"cardNo":"2"},"Skip":"3"}`},
+		{name: "secret slice type", typ: reflect.TypeOf([]*redactInner{}), in: `[{"Key":"1","Name":"n"}]`, want: `[{"Key":"***","Name":"n"}]`},
+		{name: "secret needs type", in: `{"cardNo":"2","Inner":{"Key":"3"}}`, want: `{"cardNo":"2","Inner":{"Key":"3"}}`},
+		{name: "secret and config together", fields: []string{"name"}, typ: reqType, in: `{"Name":"n","cardNo":"2"}`, want: `{"Name":"***","cardNo":"***"}`},
+		{name: "pattern in text", patterns: []string{`\d{11}`}, in: `call 13800001111 now`, want: `call *** now`},
+		{name: "pattern after fields", fields: []string{"password"}, patterns: []string{`1[3-9]\d{9}`}, in: `{"password":"p","memo":"tel 13800001111"}`, want: `{"memo":"tel ***","password":"***"}`},
+		{name: "binary", in: "\xff\xfe\x01", want: "<binary 3 bytes>"},
+		{name: "truncate", maxSize: 5, in: `abcdefgh`, want: `abcde...(8 bytes)`},
+		{name: "truncate keeps utf8", maxSize: 4, in: `中文字`, want: `中...(9 bytes)`},
+		{name: "truncate after redact", fields: []string{"password"}, maxSize: 16, in: `{"password":"0123456789"}`, want: `{"password":"***...(18 bytes)`},
+		{name: "no limit", in: strings.Repeat("a", 3000), want: strings.Repeat("a", 3000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRedactor(tt.fields, tt.patterns, tt.maxSize)
+			if got := r.apply([]byte(tt.in), secretsOf(tt.typ)); got != tt.want {
+				t.Errorf("apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretsOf(t *testing.T) {
+	tests := []struct {
+		typ  reflect.Type
+		want map[string]bool
+	}{
+		{reflect.TypeOf(redactReq{}), map[string]bool{"pin": true, "cardno": true, "inner.key": true, "items.key": true, "ptr.key": true}},
+		{reflect.TypeOf(&redactReq{}), map[string]bool{"pin": true, "cardno": true, "inner.key": true, "items.key": true, "ptr.key": true}},
+		{reflect.TypeOf([]redactInner{}), map[string]bool{"key": true}},
+		{reflect.TypeOf(redactNode{}), map[string]bool{"token": true}}, // 自引用的结构体只展开一层
+		{reflect.TypeOf(map[string]redactInner{}), map[string]bool{}},
+		{reflect.TypeOf(""), map[string]bool{}},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := secretsOf(tt.typ); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("secretsOf(%v) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestLogPayloadDefault(t *testing.T) {
+	// 模块未加载时只按类型脱敏并限制长度
+	got := logPayload("unknown-module", []byte(`{"cardNo":"1","password":"p"}`), reflect.TypeOf(redactReq{}))
+	if want := `{"cardNo":"***","password":"p"}`; got != want {
+		t.Errorf("logPayload() = %s, want %s", got, want)
+	}
+	got = logPayload("unknown-module", []byte(strings.Repeat("a", 3000)), nil)
+	if want := strings.Repeat("a", 2048) + "...(3000 bytes)"; got != want {
+		t.Errorf("logPayload() length = %d, want %d", len(got), len(want))
+	}
+}
@@ -0,0 +1,116 @@
+package qf
+
+import (
+	goContext "context"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy 请求重试策略
+// 只有幂等的请求才会在超时等错误后重试，非幂等请求仅在未连接（请求未发出）时重试
+type RetryPolicy struct {
+	MaxAttempts int             // 最大尝试次数（含首次），小于等于1时不重试
+	BaseDelay   int             // 首次重试前的等待时间（毫秒），之后每次翻倍
+	MaxDelay    int             // 最大等待时间（毫秒），0为不限制
+	Jitter      float64         // 随机抖动比例 0~1，等待时间在 [delay*(1-Jitter), delay] 之间
+	RetryCodes  []easyCon.EResp // 可重试的响应码，为nil时使用 Base.Request.RetryCodes
+}
+
+// CallOption 单次请求的选项
+type CallOption func(out *Outbound)
+
+// WithRetry 设置本次请求的重试策略，覆盖 Base.Request 中的默认策略
+func WithRetry(policy RetryPolicy) CallOption {
+	return func(out *Outbound) {
+		out.Retry = &policy
+	}
+}
+
+// WithContext 设置发起请求的上下文，ctx 结束（如处理请求的截止时间已到）后不再重试，同时沿用 ctx 所在请求的链路Id
+// CallContext 和生成的客户端已自动带上
+func WithContext(ctx goContext.Context) CallOption {
+	trace := WithTrace(ctx)
+	return func(out *Outbound) {
+		out.Ctx = ctx
+		trace(out)
+	}
+}
+
+// Idempotent 标记本次请求是幂等的，可以安全重试
+func Idempotent() CallOption {
+	return func(out *Outbound) {
+		out.Idempotent = true
+	}
+}
+
+// Idempotent 标记目标模块的路由是幂等的，发往这些路由的请求均可安全重试
+func (reg *Reg) Idempotent(module string, routes ...string) {
+	if reg.idempotent == nil {
+		reg.idempotent = map[string]bool{}
+	}
+	for _, route := range routes {
+		reg.idempotent[module+"."+route] = true
+	}
+}
+
+// retryable 响应码是否可以重试
+func (p *RetryPolicy) retryable(code easyCon.EResp, idempotent bool) bool {
+	for _, c := range p.RetryCodes {
+		if c == code {
+			return idempotent || code == easyCon.ERespUnLinked
+		}
+	}
+	return false
+}
+
+// delay 第 attempt 次失败后的等待时间，指数退避并加入随机抖动
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := float64(p.BaseDelay)
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < float64(p.MaxDelay)); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	return time.Duration(d) * time.Millisecond
+}
+
+// retryPolicy 获取请求的重试策略，未单独设置时使用模块配置
+// 单独设置的策略未指定 RetryCodes 时使用配置中的响应码，配置也为空时重试超时和未连接
+func (bll *Service) retryPolicy(out *Outbound) *RetryPolicy {
+	cfg := bll.cfg.getBase().Request
+	var codes []easyCon.EResp
+	for _, c := range cfg.RetryCodes {
+		codes = append(codes, easyCon.EResp(c))
+	}
+	if out.Retry != nil {
+		if out.Retry.RetryCodes != nil {
+			return out.Retry
+		}
+		if codes == nil {
+			codes = []easyCon.EResp{easyCon.ERespTimeout, easyCon.ERespUnLinked}
+		}
+		policy := *out.Retry
+		policy.RetryCodes = codes
+		return &policy
+	}
+	return &RetryPolicy{
+		MaxAttempts: cfg.RetryMaxAttempts,
+		BaseDelay:   cfg.RetryBaseDelay,
+		MaxDelay:    cfg.RetryMaxDelay,
+		Jitter:      cfg.RetryJitter,
+		RetryCodes:  codes,
+	}
+}
+
+// isIdempotent 请求是否幂等
+func (bll *Service) isIdempotent(out *Outbound) bool {
+	if out.Idempotent {
+		return true
+	}
+	return bll.reg != nil && bll.reg.idempotent[out.Module+"."+out.Route]
+}
@@ -0,0 +1,249 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"reflect"
+	"strings"
+)
+
+var (
+	respType  = reflect.TypeOf(easyCon.ERespSuccess)
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	iCtxType  = reflect.TypeOf((*IContext)(nil)).Elem()
+	goCtxType = reflect.TypeOf((*goContext.Context)(nil)).Elem()
+
+	// 框架内置路由，业务不可重复注册
+	builtinRoutes = map[string]bool{
+		"Exit":     true,
+		"Version":  true,
+		"Breakers": true,
+		"Describe": true,
+		"Health":   true,
+		"Ready":    true,
+		"Stats":    true,
+	}
+)
+
+// method 已校验签名的业务方法
+type method struct {
+	fn       reflect.Value
+	withCtx  bool         // 首个参数是否为上下文
+	withCode bool         // 是否返回响应码，不返回时根据错误推断
+	inType   reflect.Type // 入参类型，无入参时为nil
+	outType  reflect.Type // 返回值类型，无返回值时为nil
+	rules    *validator   // 入参的校验规则，无规则时为nil
+	strict   *bool        // 是否严格解析JSON，为nil时使用模块配置
+}
+
+// RouteOption 路由选项
+type RouteOption func(m *method)
+
+// StrictJson 设置路由是否严格解析JSON，覆盖 Base.Request.StrictJson 配置
+func StrictJson(enable bool) RouteOption {
+	return func(m *method) {
+		m.strict = &enable
+	}
+}
+
+// Handle 注册路由对应的业务方法
+// 方法签名在注册时校验，参数为 ([ctx], [in])，返回值为 (out, EResp, error)、(EResp, error)、(out, error) 或 (error)
+// 其中 ctx 可以是 qf.IContext 或 context.Context，不返回 EResp 时响应码根据错误推断，见 Error
+// 签名不合法或路由重复时直接panic，以便模块在启动阶段就暴露问题
+func (reg *Reg) Handle(route string, fn any, opts ...RouteOption) {
+	if builtinRoutes[route] {
+		panic(fmt.Errorf("route [%s] is reserved", route))
+	}
+	if _, ok := reg.routes[route]; ok {
+		panic(fmt.Errorf("route [%s] already registered", route))
+	}
+	m, err := newMethod(fn)
+	if err != nil {
+		panic(fmt.Errorf("route [%s] %v", route, err))
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if reg.routes == nil {
+		reg.routes = map[string]*method{}
+	}
+	reg.routes[route] = m
+}
+
+// Mount 将业务对象的所有导出方法注册为路由，路由名称为 prefix+方法名
+// 所有签名不合法的方法会汇总后一次性panic，避免逐个排查
+func (reg *Reg) Mount(prefix string, obj any, opts ...RouteOption) {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() || v.NumMethod() == 0 {
+		panic(fmt.Errorf("mount [%s] %T has no exported method", prefix, obj))
+	}
+
+	t := v.Type()
+	var errs []string
+	for i := 0; i < v.NumMethod(); i++ {
+		route := prefix + t.Method(i).Name
+		if _, err := newMethod(v.Method(i).Interface()); err != nil {
+			errs = append(errs, fmt.Sprintf("  %s: %v", route, err))
+			continue
+		}
+		reg.Handle(route, v.Method(i).Interface(), opts...)
+	}
+	if len(errs) > 0 {
+		panic(fmt.Errorf("mount [%s] %T invalid methods:\n%s", prefix, obj, strings.Join(errs, "\n")))
+	}
+}
+
+// Use 注册请求中间件，作用于所有请求（包括 Exit、Describe、Stats 等内置路由），先注册的在外层
+// 中间件不调用 next 即可拒绝请求，例如鉴权失败时直接返回错误
+func (reg *Reg) Use(middleware ...Middleware) {
+	reg.middlewares = append(reg.middlewares, middleware...)
+}
+
+// newMethod 校验方法签名
+func newMethod(fn any) (*method, error) {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("invalid method")
+	}
+
+	t := v.Type()
+	// 检查是否是函数
+	if t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("not a function: %T", fn)
+	}
+	if v.IsNil() {
+		return nil, fmt.Errorf("method %T is nil", fn)
+	}
+
+	m := &method{fn: v}
+
+	// 检查参数，首个参数为上下文时可额外带一个入参
+	numIn := t.NumIn()
+	if numIn > 0 && (t.In(0) == iCtxType || t.In(0) == goCtxType) {
+		m.withCtx = true
+		numIn--
+	}
+	if numIn > 1 {
+		return nil, fmt.Errorf("method %T too many arguments, expect [ctx] and 0 or 1 param", fn)
+	}
+	if numIn == 1 {
+		m.inType = t.In(t.NumIn() - 1)
+		rules, err := getValidator(m.inType)
+		if err != nil {
+			return nil, fmt.Errorf("method %T param %v", fn, err)
+		}
+		m.rules = rules
+	}
+
+	// 检查返回值，最后一个必须是error，倒数第二个为EResp时表示返回响应码
+	numOut := t.NumOut()
+	if numOut < 1 || numOut > 3 || t.Out(numOut-1) != errorType {
+		return nil, fmt.Errorf("method %T invalid return types, need [any],[code],error", fn)
+	}
+	numOut--
+	if numOut > 0 && t.Out(numOut-1) == respType {
+		m.withCode = true
+		numOut--
+	}
+	switch numOut {
+	case 0:
+	case 1:
+		m.outType = t.Out(0)
+	default:
+		return nil, fmt.Errorf("method %T invalid return types, need [any],[code],error", fn)
+	}
+	return m, nil
+}
+
+// call 执行业务方法
+func (m *method) call(ctx IContext, pack easyCon.PackReq) (code easyCon.EResp, resp []byte) {
+	codec := ctxCodec(ctx)
+	inCodec := codec
+	if m.strict != nil {
+		inCodec = withStrict(codec, *m.strict)
+	}
+
+	var args []reflect.Value
+	if m.withCtx {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+	if m.inType != nil {
+		arg, err := decodeContent(m.inType, pack.Content, inCodec)
+		if err != nil {
+			return easyCon.ERespBadReq, []byte(err.Error())
+		}
+		if err = validateValue(m.rules, arg); err != nil {
+			return easyCon.ERespBadReq, encodeError(easyCon.ERespBadReq, err)
+		}
+		args = append(args, arg)
+	}
+
+	// 调用方法
+	results := m.fn.Call(args)
+
+	// 处理返回
+	last := len(results) - 1
+	err, _ := results[last].Interface().(error)
+	if m.withCode {
+		code = results[last-1].Interface().(easyCon.EResp)
+	} else {
+		code = errorCode(err)
+	}
+	if code != easyCon.ERespSuccess {
+		return code, encodeError(code, err)
+	}
+	if m.outType == nil {
+		return code, nil
+	}
+	resp, err = encodeContent(results[0].Interface(), codec)
+	if err != nil {
+		return easyCon.ERespError, []byte(fmt.Sprintf("failed to marshal response: %v", err))
+	}
+	return code, resp
+}
+
+// ctxCodec 获取上下文中请求内容的编解码
+func ctxCodec(ctx IContext) ICodec {
+	if c, ok := ctx.(*context); ok {
+		return c.payloadCodec()
+	}
+	return jsonCodec{}
+}
+
+// decodeContent 将请求或响应内容转换为指定类型的值
+// string 和 []byte 类型直接转换，其他类型使用编解码解析
+func decodeContent(t reflect.Type, content []byte, codec ICodec) (reflect.Value, error) {
+	if isRawType(t) {
+		if t.Kind() == reflect.String {
+			return reflect.ValueOf(string(content)).Convert(t), nil
+		}
+		return reflect.ValueOf(content).Convert(t), nil
+	}
+	obj := reflect.New(t)
+	if err := codec.Unmarshal(content, obj.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return obj.Elem(), nil
+}
+
+// encodeContent 将值转换为请求或响应内容，与 decodeContent 对应
+func encodeContent(obj any, codec ICodec) ([]byte, error) {
+	switch v := obj.(type) {
+	case nil:
+		return []byte(""), nil
+	case string:
+		// 如果是字符串，直接转换为 []byte
+		return []byte(v), nil
+	case []byte:
+		// 如果已经是 []byte，直接使用
+		return v, nil
+	}
+	// 其他类型（结构体等）使用编解码转换
+	return codec.Marshal(obj)
+}
+
+// isRawType 是否为不经 JSON 转换、直接使用原始内容的类型
+func isRawType(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
@@ -0,0 +1,262 @@
+package qf
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema JSON Schema
+type Schema map[string]any
+
+// Contract 模块对外的接口约定，通过内置路由 Describe 获取
+// 结构体类型统一放在 Defs 中，其他位置通过 {"$ref":"#/$defs/类型名"} 引用
+type Contract struct {
+	Module     string
+	Desc       string
+	Version    string
+	Routes     []RouteContract   // 通过 Handle/Mount 注册的路由，OnReq 中处理的路由无法获取
+	Subscribes []NoticeContract  // 通过 Subscribe 订阅的通知
+	Publishes  []NoticeContract  // 通过 DeclarePublish 声明的发出通知
+	Defs       map[string]Schema `json:"$defs,omitempty"`
+}
+
+// RouteContract 路由的约定
+type RouteContract struct {
+	Route    string
+	Request  Schema `json:",omitempty"` // 请求内容，无入参时为空
+	Response Schema `json:",omitempty"` // 响应内容，无返回值时为空
+}
+
+// NoticeContract 通知的约定
+type NoticeContract struct {
+	Route   string // 通知路由，订阅时为匹配规则
+	Retain  bool   // 是否为保留通知
+	Message Schema // 通知内容
+}
+
+// publishDecl 声明的发出通知
+type publishDecl struct {
+	route   string
+	retain  bool
+	msgType reflect.Type
+}
+
+// DeclarePublish 声明模块会发出的通知及其内容类型，仅用于 Describe 输出约定，不影响发送
+func DeclarePublish[T any](reg *Reg, route string, retain bool) {
+	reg.publishes = append(reg.publishes, &publishDecl{route: route, retain: retain, msgType: reflect.TypeOf((*T)(nil)).Elem()})
+}
+
+// describe 根据注册信息生成模块的接口约定
+func describe(reg *Reg, cfg *Config) Contract {
+	g := &schemaGen{defs: map[string]Schema{}}
+	c := Contract{Module: cfg.module, Desc: cfg.desc, Version: cfg.version}
+
+	names := make([]string, 0, len(reg.routes))
+	for name := range reg.routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m := reg.routes[name]
+		rc := RouteContract{Route: name}
+		if m.inType != nil {
+			rc.Request = g.content(m.inType)
+		}
+		if m.outType != nil {
+			rc.Response = g.content(m.outType)
+		}
+		c.Routes = append(c.Routes, rc)
+	}
+	for _, s := range reg.subscribers {
+		c.Subscribes = append(c.Subscribes, NoticeContract{Route: s.pattern, Retain: s.retain, Message: g.content(s.msgType)})
+	}
+	for _, p := range reg.publishes {
+		c.Publishes = append(c.Publishes, NoticeContract{Route: p.route, Retain: p.retain, Message: g.content(p.msgType)})
+	}
+	if len(g.defs) > 0 {
+		c.Defs = g.defs
+	}
+	return c
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaGen JSON Schema 生成器，结构体生成到 defs 中以支持自引用
+type schemaGen struct {
+	defs map[string]Schema
+}
+
+// content 生成请求、响应或通知内容的 JSON Schema，与 encodeContent 的编码方式对应
+// string 和 []byte 原样传递，其他类型按JSON描述
+func (g *schemaGen) content(t reflect.Type) Schema {
+	if isRawType(t) {
+		return Schema{"type": "string"}
+	}
+	return g.schema(t)
+}
+
+// schema 生成类型的 JSON Schema
+func (g *schemaGen) schema(t reflect.Type) Schema {
+	t = derefType(t)
+	switch t {
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case jsonNumberType:
+		return Schema{"type": "number"}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		// 自定义编码的类型无法推断
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		name := strings.ReplaceAll(t.String(), " ", "")
+		if t.Name() == "" {
+			// 匿名结构体直接展开
+			return g.object(t)
+		}
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = Schema{}
+			g.defs[name] = g.object(t)
+		}
+		return Schema{"$ref": "#/$defs/" + name}
+	}
+	return Schema{}
+}
+
+// object 生成结构体的 JSON Schema，qf 标签中的校验规则转换为对应的约束
+func (g *schemaGen) object(t reflect.Type) Schema {
+	props := Schema{}
+	var required []string
+	g.fields(t, props, &required)
+	s := Schema{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// fields 收集结构体字段，匿名嵌入的结构体与 JSON 编码一样展开到上层
+func (g *schemaGen) fields(t reflect.Type, props Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && strings.Split(tag, ",")[0] == "" && derefType(f.Type).Kind() == reflect.Struct {
+			g.fields(derefType(f.Type), props, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name := jsonFieldName(f)
+		fs := g.schema(f.Type)
+		rules, _ := parseRules(f)
+		if len(rules) > 0 {
+			fs = applyRules(fs, f.Type, rules, required, name)
+		}
+		props[name] = fs
+	}
+}
+
+// applyRules 将校验规则转换为 JSON Schema 约束，引用类型的约束通过 allOf 组合
+// 声明 omitempty 时零值不受约束，通过 anyOf 组合零值
+func applyRules(s Schema, t reflect.Type, rules []*rule, required *[]string, name string) Schema {
+	out := Schema{}
+	for k, v := range s {
+		out[k] = v
+	}
+	if ref, ok := s["$ref"]; ok {
+		out = Schema{"allOf": []Schema{{"$ref": ref}}}
+	}
+
+	kind := derefType(t).Kind()
+	for _, r := range rules {
+		switch r.name {
+		case "required":
+			*required = append(*required, name)
+		case "secret":
+			out["writeOnly"] = true
+		case "min", "max":
+			key := "minimum"
+			switch {
+			case kind == reflect.String:
+				key = "minLength"
+			case kind == reflect.Map:
+				key = "minProperties"
+			case hasLen(kind):
+				key = "minItems"
+			}
+			if r.name == "max" {
+				key = "max" + key[3:]
+			}
+			out[key] = r.num
+		case "oneof":
+			if !isNumberKind(kind) {
+				out["enum"] = r.options
+				break
+			}
+			var nums []float64
+			for _, o := range r.options {
+				if n, err := strconv.ParseFloat(o, 64); err == nil {
+					nums = append(nums, n)
+				}
+			}
+			out["enum"] = nums
+		case "regex":
+			out["pattern"] = r.param
+		}
+	}
+	for _, r := range rules {
+		if r.name != "omitempty" {
+			continue
+		}
+		if zero := zeroSchema(kind); zero != nil {
+			return Schema{"anyOf": []Schema{out, zero}}
+		}
+	}
+	return out
+}
+
+// zeroSchema 零值对应的 JSON Schema，结构体等无法表示时返回nil
+func zeroSchema(kind reflect.Kind) Schema {
+	switch {
+	case isNumberKind(kind):
+		return Schema{"const": 0}
+	case kind == reflect.String:
+		return Schema{"const": ""}
+	case kind == reflect.Bool:
+		return Schema{"const": false}
+	case kind == reflect.Slice || kind == reflect.Map:
+		return Schema{"type": "null"}
+	}
+	return nil
+}
@@ -1,17 +1,20 @@
 package qf
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
+	goContext "context"
 	easyCon "github.com/qiu-tec/easy-con.golang"
-	"time"
+	"log/slog"
 )
 
 type Service struct {
 	adapter easyCon.IAdapter
 	cfg     IConfig
 	reg     *Reg
+	ctx     goContext.Context // 模块运行期间的上下文，停止时取消
+
+	breakers breakerSet    // 按目标路由的熔断器
+	logger   *slog.Logger  // 结构化日志
+	logFile  *rotateWriter // 日志文件
 }
 
 // GetRegEvents 获取注册绑定事件
@@ -34,13 +37,16 @@ func (bll *Service) Load(moduleName, moduleDesc, moduleVersion string, customSec
 	bll.cfg.setBase(moduleName, moduleDesc, moduleVersion, customSectionName)
 	// 加载配置
 	loadConfig(bll.cfg)
+	// 按配置创建日志
+	bll.initLogger()
 }
 
 // NoticeInvoke 调用通知实现方法
 func (bll *Service) NoticeInvoke(pack easyCon.PackNotice, onReq OnNoticeFunc) {
-	ctx, err := newContent(pack.Content, nil, nil, &pack)
+	ctx, err := newNoticeContext(goContext.Background(), pack, bll.cfg.getBase().Request.StrictJson)
 	if err != nil {
-		bll.SendLogError(fmt.Sprintln("NoticeInvoke build invoke error", pack), err)
+		bll.Logger().Error("NoticeInvoke build invoke error", "from", pack.From, "route", pack.Route, "inParam", logPayload(bll.Name(), pack.Content, nil), "error", err)
+		return
 	}
 	onReq(ctx)
 }
@@ -60,76 +66,76 @@ func (bll *Service) ReturnNotFind() (code easyCon.EResp, resp []byte) {
 	return easyCon.ERespRouteNotFind, nil
 }
 
-// SendRequest 发送请求
-func (bll *Service) SendRequest(module, route string, params []byte) easyCon.PackResp {
-	resp := bll.adapter.Req(module, route, params)
-	if resp.RespCode != easyCon.ERespSuccess {
-		// 记录日志
-		str, _ := json.Marshal(params)
-		err := errors.New(formatRespError(resp.RespCode, string(resp.Content)))
-		bll.SendLogError(fmt.Sprintf("[SendRequest To %s.%s] InParams=%s", module, route, string(str)), err)
-	}
-	return resp
+// SendRequest 发送请求，params 原样发送，不使用 Base.Request.Codec
+func (bll *Service) SendRequest(module, route string, params []byte, opts ...CallOption) easyCon.PackResp {
+	return bll.sendOut(&Outbound{Kind: EOutKindRequest, Module: module, Route: route, Content: params}, opts...)
 }
 
 // SendRequestWithTimeout 发送请求(可自定义超时时间的,单位毫秒)
-func (bll *Service) SendRequestWithTimeout(module, route string, params []byte, timeout int) easyCon.PackResp {
-	resp := bll.adapter.ReqWithTimeout(module, route, params, timeout)
-	if resp.RespCode != easyCon.ERespSuccess {
-		// 记录日志
-		str, _ := json.Marshal(params)
-		err := errors.New(formatRespError(resp.RespCode, string(resp.Content)))
-		bll.SendLogError(fmt.Sprintf("[SendRequestWithTimeout To %s.%s] InParams=%s", module, route, string(str)), err)
-	}
-	return resp
+// 启用 Base.Request.Header 时超时时间会随请求传给被调用方，被调用方的上下文在超时后自动取消
+func (bll *Service) SendRequestWithTimeout(module, route string, params []byte, timeout int, opts ...CallOption) easyCon.PackResp {
+	return bll.sendOut(&Outbound{Kind: EOutKindRequest, Module: module, Route: route, Content: params, Timeout: timeout}, opts...)
 }
 
-// SendNotice 发送通知
+// SendNotice 发送通知，content 原样发送，不使用 Base.Request.Codec
 func (bll *Service) SendNotice(route string, content []byte) {
-	err := bll.adapter.SendNotice(route, content)
-	if err != nil {
-		str, _ := json.Marshal(content)
-		bll.SendLogError(fmt.Sprintf("[SendNotice To %s] InParams=%s", route, string(str)), err)
-	}
+	bll.sendOut(&Outbound{Kind: EOutKindNotice, Route: route, Content: content})
 }
 
 // SendRetainNotice 发送保持通知
 func (bll *Service) SendRetainNotice(route string, content []byte) {
-	err := bll.adapter.SendRetainNotice(route, content)
-	if err != nil {
-		str, _ := json.Marshal(content)
-		bll.SendLogError(fmt.Sprintf("[SendRetainNotice To %s] InParams=%s", route, string(str)), err)
-	}
+	bll.sendOut(&Outbound{Kind: EOutKindRetainNotice, Route: route, Content: content})
 }
 
-// SendLogDebug 发送Debug日志
+// ClearRetainNotice 清除保持通知，之后订阅的模块不会再收到
+func (bll *Service) ClearRetainNotice(route string) {
+	bll.sendOut(&Outbound{Kind: EOutKindClearRetainNotice, Route: route})
+}
+
+// SendLogDebug 发送Debug日志，等同于 Logger().Debug
 func (bll *Service) SendLogDebug(content string) {
-	fmt.Println(fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04:05"), content))
-	bll.adapter.Debug(content)
+	logCaller(goContext.Background(), bll.Logger(), 1, slog.LevelDebug, content)
 }
 
-// SendLogWarn 发送Warn日志
+// SendLogWarn 发送Warn日志，等同于 Logger().Warn
 func (bll *Service) SendLogWarn(content string) {
-	bll.adapter.Warn(content)
+	logCaller(goContext.Background(), bll.Logger(), 1, slog.LevelWarn, content)
 }
 
-// SendLogError 发送Error日志
+// SendLogError 发送Error日志，等同于 Logger().Error
 func (bll *Service) SendLogError(content string, err error) {
-	// 记录日志
-	errStr := ""
-	if err != nil {
-		errStr = err.Error()
-	}
-	writeLog(bll.cfg.getBase().module, "Error", content, errStr)
+	bll.sendLogError(goContext.Background(), content, err)
+}
 
-	bll.adapter.Err(content, err)
+// SendLogDebugCtx 发送Debug日志，处理请求时传入请求的 IContext，日志带有 traceId 和 reqId
+func (bll *Service) SendLogDebugCtx(ctx goContext.Context, content string) {
+	logCaller(ctx, bll.Logger(), 1, slog.LevelDebug, content)
+}
+
+// SendLogWarnCtx 发送Warn日志，处理请求时传入请求的 IContext，日志带有 traceId 和 reqId
+func (bll *Service) SendLogWarnCtx(ctx goContext.Context, content string) {
+	logCaller(ctx, bll.Logger(), 1, slog.LevelWarn, content)
+}
+
+// SendLogErrorCtx 发送Error日志，处理请求时传入请求的 IContext，日志带有 traceId 和 reqId
+func (bll *Service) SendLogErrorCtx(ctx goContext.Context, content string, err error) {
+	bll.sendLogError(ctx, content, err)
+}
+
+func (bll *Service) sendLogError(ctx goContext.Context, content string, err error) {
+	if err == nil {
+		logCaller(ctx, bll.Logger(), 2, slog.LevelError, content)
+		return
+	}
+	logCaller(ctx, bll.Logger(), 2, slog.LevelError, content, "error", err)
 }
 
 func (bll *Service) config() IConfig {
 	return bll.cfg
 }
 
-func (bll *Service) setEnv(reg *Reg, adapter easyCon.IAdapter) {
+func (bll *Service) setEnv(reg *Reg, adapter easyCon.IAdapter, ctx goContext.Context) {
 	bll.reg = reg
 	bll.adapter = adapter
+	bll.ctx = ctx
 }
@@ -0,0 +1,139 @@
+package qf
+
+import (
+	easyCon "github.com/qiu-tec/easy-con.golang"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 保留的连接状态变化记录条数
+const linkHistorySize = 100
+
+// Stats 模块运行统计，通过内置路由 Stats 获取
+type Stats struct {
+	Module      string
+	StartTime   string
+	Uptime      float64 // 运行时长（秒）
+	Goroutines  int
+	Memory      MemStats
+	Routes      []RouteStats // 各路由的请求统计，不含内置路由，按路由排序
+	LinkState   string       // 当前连接状态
+	LinkHistory []LinkEvent  // 最近的连接状态变化
+}
+
+// MemStats 内存统计
+type MemStats struct {
+	HeapAlloc   uint64  // 堆上已分配的字节数
+	HeapInuse   uint64  // 堆上正在使用的字节数
+	HeapSys     uint64  // 向系统申请的堆字节数
+	HeapObjects uint64  // 堆上的对象数
+	NumGC       uint32  // GC次数
+	PauseTotal  float64 // GC累计暂停时间（毫秒）
+}
+
+// RouteStats 单个路由的请求统计
+type RouteStats struct {
+	Route      string
+	Count      int64   // 请求次数
+	Errors     int64   // 失败次数
+	AvgLatency float64 // 平均耗时（毫秒）
+	MaxLatency float64 // 最大耗时（毫秒）
+}
+
+// LinkEvent 连接状态变化
+type LinkEvent struct {
+	Time  string
+	State string
+}
+
+// moduleStats 模块运行统计的采集
+type moduleStats struct {
+	lock      sync.Mutex
+	startTime time.Time
+	routes    map[string]*routeStats
+	linkState string
+	history   []LinkEvent
+}
+
+type routeStats struct {
+	count, errors int64
+	total, max    time.Duration
+}
+
+func newModuleStats() *moduleStats {
+	return &moduleStats{startTime: time.Now(), routes: map[string]*routeStats{}}
+}
+
+// recordReq 记录请求处理结果，只记录分发到业务路由的请求，其他请求由调用方跳过
+func (s *moduleStats) recordReq(route string, code easyCon.EResp, elapsed time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	rs, ok := s.routes[route]
+	if !ok {
+		rs = &routeStats{}
+		s.routes[route] = rs
+	}
+	rs.count++
+	if code != easyCon.ERespSuccess {
+		rs.errors++
+	}
+	rs.total += elapsed
+	if elapsed > rs.max {
+		rs.max = elapsed
+	}
+}
+
+// recordLink 记录连接状态变化
+func (s *moduleStats) recordLink(status easyCon.EStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.linkState = string(status)
+	s.history = append(s.history, LinkEvent{Time: time.Now().Format("2006-01-02 15:04:05.000"), State: string(status)})
+	if len(s.history) > linkHistorySize {
+		s.history = s.history[len(s.history)-linkHistorySize:]
+	}
+}
+
+// snapshot 获取当前统计
+func (s *moduleStats) snapshot(module string) Stats {
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	st := Stats{
+		Module:     module,
+		StartTime:  s.startTime.Format("2006-01-02 15:04:05"),
+		Uptime:     time.Since(s.startTime).Seconds(),
+		Goroutines: runtime.NumGoroutine(),
+		Memory: MemStats{
+			HeapAlloc:   mem.HeapAlloc,
+			HeapInuse:   mem.HeapInuse,
+			HeapSys:     mem.HeapSys,
+			HeapObjects: mem.HeapObjects,
+			NumGC:       mem.NumGC,
+			PauseTotal:  float64(mem.PauseTotalNs) / 1e6,
+		},
+		Routes:      make([]RouteStats, 0, len(s.routes)),
+		LinkState:   s.linkState,
+		LinkHistory: append([]LinkEvent{}, s.history...),
+	}
+	for route, rs := range s.routes {
+		st.Routes = append(st.Routes, RouteStats{
+			Route:      route,
+			Count:      rs.count,
+			Errors:     rs.errors,
+			AvgLatency: float64(rs.total.Microseconds()) / 1000 / float64(rs.count),
+			MaxLatency: float64(rs.max.Microseconds()) / 1000,
+		})
+	}
+	sort.Slice(st.Routes, func(i, j int) bool {
+		return st.Routes[i].Route < st.Routes[j].Route
+	})
+	return st
+}
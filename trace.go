@@ -0,0 +1,54 @@
+package qf
+
+import (
+	goContext "context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+)
+
+// traceKey 请求链路信息在 context 中的键
+type traceKey struct{}
+
+// traceInfo 请求链路信息
+type traceInfo struct {
+	traceId string // 链路Id，随请求在模块间传递
+	reqId   uint64 // 当前处理的请求Id
+}
+
+// WithTrace 沿用 ctx 所在请求的链路Id，处理请求时发出的请求应带上此选项或 WithContext（CallContext 和生成的客户端已自动带上），
+// 调用方和各级被调用方的日志中 traceId 相同，可据此串起一次完整的业务流程
+// 链路Id通过请求头传递，需启用 Base.Request.Header，未启用时被调用方生成新的链路Id
+//
+//	resp, err := qf.Call[Info](s, "ModuleB", "Get", req, qf.WithTrace(ctx))
+func WithTrace(ctx goContext.Context) CallOption {
+	return func(out *Outbound) {
+		if t := traceOf(ctx); t != nil {
+			out.TraceId = t.traceId
+		}
+	}
+}
+
+// withTrace 将链路信息放入 context
+func withTrace(parent goContext.Context, traceId string, reqId uint64) goContext.Context {
+	return goContext.WithValue(parent, traceKey{}, &traceInfo{traceId: traceId, reqId: reqId})
+}
+
+// traceOf 获取 context 中的链路信息，不存在时返回nil
+func traceOf(ctx goContext.Context) *traceInfo {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(traceKey{}).(*traceInfo)
+	return t
+}
+
+// attrs 链路信息对应的日志字段
+func (t *traceInfo) attrs() []slog.Attr {
+	return []slog.Attr{slog.String("traceId", t.traceId), slog.Uint64("reqId", t.reqId)}
+}
+
+// newTraceId 生成新的链路Id
+func newTraceId() string {
+	return fmt.Sprintf("%016x", rand.Uint64())
+}
@@ -0,0 +1,312 @@
+package qf
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Field   string // 字段路径，如 Info.Name、Items[0].Id
+	Rule    string // 未通过的规则
+	Message string // 错误信息
+}
+
+// 已解析的结构体校验规则，reflect.Type -> *validator
+var validators = sync.Map{}
+
+// validator 结构体的校验规则
+// 通过 qf 标签声明，多个规则用逗号分隔，例如 `qf:"required,min=1,max=20,oneof=a b c,regex=^[a-z]+$"`
+//   - required 不能为零值
+//   - omitempty 值为零值时不校验其他规则，未声明时零值同样需满足 min/max/oneof/regex，空指针不校验
+//   - min/max 数值比较大小，字符串比较字符数，切片和map比较元素数
+//   - oneof 取值必须为空格分隔的选项之一
+//   - regex 字符串需匹配正则，因正则中可能包含逗号，regex 必须放在最后
+//   - secret 不参与校验，该字段在本类型的参数日志中按路径脱敏，其他类型的同名字段不受影响
+type validator struct {
+	fields []*fieldRules
+}
+
+// fieldRules 单个字段的校验规则
+type fieldRules struct {
+	index     int
+	name      string
+	rules     []*rule
+	omitEmpty bool       // 零值时跳过校验
+	nested    *validator // 嵌套结构体（或其指针、切片）的校验规则
+}
+
+// rule 单条校验规则
+type rule struct {
+	name    string
+	param   string
+	num     float64
+	options []string
+	re      *regexp.Regexp
+}
+
+// getValidator 获取类型的校验规则，类型中没有任何规则时返回nil
+func getValidator(t reflect.Type) (*validator, error) {
+	if v, ok := validators.Load(t); ok {
+		return v.(*validator), nil
+	}
+	v, err := buildValidator(t, map[reflect.Type]*validator{})
+	if err != nil {
+		return nil, err
+	}
+	validators.Store(t, v)
+	return v, nil
+}
+
+// buildValidator 解析类型的校验规则，seen 用于处理自引用的结构体
+func buildValidator(t reflect.Type, seen map[reflect.Type]*validator) (*validator, error) {
+	t = elemStructType(t)
+	if t == nil {
+		return nil, nil
+	}
+	if v, ok := seen[t]; ok {
+		return v, nil
+	}
+	v := &validator{}
+	seen[t] = v
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fr := &fieldRules{index: i, name: jsonFieldName(f)}
+		rules, err := parseRules(f)
+		if err != nil {
+			return nil, err
+		}
+		fr.rules = rules
+		for _, r := range rules {
+			if r.name == "omitempty" {
+				fr.omitEmpty = true
+			}
+		}
+		nested, err := buildValidator(f.Type, seen)
+		if err != nil {
+			return nil, err
+		}
+		fr.nested = nested
+		if len(fr.rules) > 0 || fr.nested != nil {
+			v.fields = append(v.fields, fr)
+		}
+	}
+	if len(v.fields) == 0 {
+		seen[t] = nil
+		return nil, nil
+	}
+	return v, nil
+}
+
+// parseRules 解析字段的 qf 标签
+func parseRules(f reflect.StructField) ([]*rule, error) {
+	tag := f.Tag.Get("qf")
+	if tag == "" {
+		return nil, nil
+	}
+
+	var rules []*rule
+	kind := derefType(f.Type).Kind()
+	for tag != "" {
+		item := tag
+		if strings.HasPrefix(item, "regex=") {
+			tag = ""
+		} else if i := strings.Index(item, ","); i >= 0 {
+			item, tag = item[:i], item[i+1:]
+		} else {
+			tag = ""
+		}
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		r := &rule{name: item}
+		if i := strings.Index(item, "="); i >= 0 {
+			r.name, r.param = item[:i], item[i+1:]
+		}
+		var err error
+		switch r.name {
+		case "required", "omitempty", "secret":
+		case "min", "max":
+			if !isNumberKind(kind) && !hasLen(kind) {
+				return nil, fmt.Errorf("field %s: %s not supported on %s", f.Name, r.name, f.Type)
+			}
+			r.num, err = strconv.ParseFloat(r.param, 64)
+		case "oneof":
+			r.options = strings.Fields(r.param)
+			if len(r.options) == 0 {
+				err = fmt.Errorf("no options")
+			}
+		case "regex":
+			if kind != reflect.String {
+				return nil, fmt.Errorf("field %s: regex not supported on %s", f.Name, f.Type)
+			}
+			r.re, err = regexp.Compile(r.param)
+		default:
+			return nil, fmt.Errorf("field %s: unknown rule %q", f.Name, r.name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid rule %q: %v", f.Name, item, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// validate 校验值，错误追加到errs中
+func (v *validator) validate(val reflect.Value, path string, errs *[]FieldError) {
+	val = reflect.Indirect(val)
+	if !val.IsValid() {
+		return
+	}
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			v.validate(val.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+
+	for _, fr := range v.fields {
+		name := fr.name
+		if path != "" {
+			name = path + "." + name
+		}
+		fv := val.Field(fr.index)
+		if fr.omitEmpty && fv.IsZero() {
+			continue
+		}
+		for _, r := range fr.rules {
+			if msg := r.check(fv); msg != "" {
+				*errs = append(*errs, FieldError{Field: name, Rule: r.name, Message: msg})
+				break
+			}
+		}
+		if fr.nested != nil {
+			fr.nested.validate(fv, name, errs)
+		}
+	}
+}
+
+// check 校验单条规则，通过时返回空字符串
+func (r *rule) check(val reflect.Value) string {
+	if r.name == "required" {
+		if val.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+
+	// 空指针只校验 required
+	val = reflect.Indirect(val)
+	if !val.IsValid() {
+		return ""
+	}
+
+	switch r.name {
+	case "min", "max":
+		n, subject := float64(0), "value"
+		switch {
+		case isNumberKind(val.Kind()):
+			n = toFloat(val)
+		case val.Kind() == reflect.String:
+			n, subject = float64(utf8.RuneCountInString(val.String())), "length"
+		default:
+			n, subject = float64(val.Len()), "length"
+		}
+		if r.name == "min" && n < r.num {
+			return fmt.Sprintf("%s must be >= %s", subject, r.param)
+		}
+		if r.name == "max" && n > r.num {
+			return fmt.Sprintf("%s must be <= %s", subject, r.param)
+		}
+	case "oneof":
+		s := fmt.Sprint(val.Interface())
+		for _, o := range r.options {
+			if s == o {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", r.param)
+	case "regex":
+		if !r.re.MatchString(val.String()) {
+			return fmt.Sprintf("must match %s", r.param)
+		}
+	}
+	return ""
+}
+
+// validateValue 按类型的 qf 标签校验值，未通过时返回 BadRequest 错误，详情为 []FieldError
+func validateValue(v *validator, val reflect.Value) error {
+	if v == nil {
+		return nil
+	}
+	var errs []FieldError
+	v.validate(val, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, fmt.Sprintf("%s %s", e.Field, e.Message))
+	}
+	return BadRequest("validation failed: %s", strings.Join(msgs, "; ")).WithDetails(errs)
+}
+
+// elemStructType 获取结构体（或其指针、切片、数组）的结构体类型，不是结构体时返回nil
+func elemStructType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// jsonFieldName 获取字段在 JSON 中的名称
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+func hasLen(k reflect.Kind) bool {
+	return k == reflect.String || k == reflect.Slice || k == reflect.Map || k == reflect.Array
+}
+
+func toFloat(val reflect.Value) float64 {
+	switch {
+	case val.CanInt():
+		return float64(val.Int())
+	case val.CanUint():
+		return float64(val.Uint())
+	}
+	return val.Float()
+}
@@ -0,0 +1,159 @@
+package qf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	easyCon "github.com/qiu-tec/easy-con.golang"
+)
+
+type valSub struct {
+	V string `qf:"required"`
+}
+
+type valItem struct {
+	Id   int `qf:"required"`
+	Subs []valSub
+}
+
+type valReq struct {
+	Name  string   `qf:"required,min=2,max=4"`
+	Age   int      `qf:"min=18,max=60"`
+	Score float64  `qf:"omitempty,min=1"`
+	Tags  []string `qf:"omitempty,min=1,max=2"`
+	Kind  string   `qf:"oneof=a b"`
+	Level int      `qf:"omitempty,oneof=1 2 3"`
+	Code  string   `qf:"omitempty,regex=^[a-z]{1,3},x$"`
+	Ptr   *int     `qf:"min=1"`
+	Items []valItem
+	Info  *valItem `json:"info"`
+}
+
+// validReq 通过所有校验的请求
+func validReq() valReq {
+	return valReq{Name: "bob", Age: 18, Kind: "a"}
+}
+
+// fieldErrors 校验值并返回 字段:规则 列表
+func fieldErrors(t *testing.T, v any) []string {
+	t.Helper()
+	rules, err := getValidator(reflect.TypeOf(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = validateValue(rules, reflect.ValueOf(v))
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Code != easyCon.ERespBadReq {
+		t.Fatalf("error = %v, want BadRequest", err)
+	}
+	var got []string
+	for _, fe := range e.Details.([]FieldError) {
+		got = append(got, fe.Field+":"+fe.Rule)
+	}
+	return got
+}
+
+func TestValidate(t *testing.T) {
+	zero, one := 0, 1
+	tests := []struct {
+		name string
+		edit func(r *valReq)
+		want []string
+	}{
+		{"valid", func(r *valReq) {}, nil},
+		{"zero value", func(r *valReq) { *r = valReq{} }, []string{"Name:required", "Age:min", "Kind:oneof"}},
+		{"string min counts runes", func(r *valReq) { r.Name = "中" }, []string{"Name:min"}},
+		{"string max counts runes", func(r *valReq) { r.Name = "中文中文" }, nil},
+		{"string max", func(r *valReq) { r.Name = "abcde" }, []string{"Name:max"}},
+		{"number max", func(r *valReq) { r.Age = 61 }, []string{"Age:max"}},
+		{"omitempty checks non-zero", func(r *valReq) { r.Score = 0.5 }, []string{"Score:min"}},
+		{"omitempty empty slice is not zero", func(r *valReq) { r.Tags = []string{} }, []string{"Tags:min"}},
+		{"slice max", func(r *valReq) { r.Tags = []string{"a", "b", "c"} }, []string{"Tags:max"}},
+		{"oneof number", func(r *valReq) { r.Level = 4 }, []string{"Level:oneof"}},
+		{"oneof number valid", func(r *valReq) { r.Level = 2 }, nil},
+		{"regex with comma", func(r *valReq) { r.Code = "ab,x" }, nil},
+		{"regex", func(r *valReq) { r.Code = "ab" }, []string{"Code:regex"}},
+		{"nil pointer skipped", func(r *valReq) { r.Ptr = nil }, nil},
+		{"pointer checked", func(r *valReq) { r.Ptr = &zero }, []string{"Ptr:min"}},
+		{"pointer valid", func(r *valReq) { r.Ptr = &one }, nil},
+		{"nested path", func(r *valReq) {
+			r.Items = []valItem{{Id: 1}, {Id: 2, Subs: []valSub{{V: "x"}, {}}}, {}}
+		}, []string{"Items[1].Subs[1].V:required", "Items[2].Id:required"}},
+		{"nested pointer", func(r *valReq) { r.Info = &valItem{Subs: []valSub{{}}} }, []string{"info.Id:required", "info.Subs[0].V:required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validReq()
+			tt.edit(&r)
+			if got := fieldErrors(t, r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSlice(t *testing.T) {
+	// 参数本身为切片时按下标校验每个元素
+	got := fieldErrors(t, []*valItem{{Id: 1}, nil, {}})
+	if want := []string{"[2].Id:required"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+}
+
+func TestValidatorNoRules(t *testing.T) {
+	type plain struct {
+		Name  string
+		Inner struct{ Id int }
+	}
+	for _, typ := range []reflect.Type{reflect.TypeOf(plain{}), reflect.TypeOf(""), reflect.TypeOf([]int{})} {
+		v, err := getValidator(typ)
+		if v != nil || err != nil {
+			t.Errorf("getValidator(%v) = %v, %v, want nil", typ, v, err)
+		}
+	}
+}
+
+func TestValidatorInvalidTags(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want string
+	}{
+		{"unknown rule", reflect.TypeOf(struct {
+			A string `qf:"requird"`
+		}{}), `field A: unknown rule "requird"`},
+		{"min on bool", reflect.TypeOf(struct {
+			B bool `qf:"min=1"`
+		}{}), "field B: min not supported on bool"},
+		{"min not a number", reflect.TypeOf(struct {
+			C int `qf:"min=x"`
+		}{}), `field C: invalid rule "min=x"`},
+		{"oneof without options", reflect.TypeOf(struct {
+			D string `qf:"oneof="`
+		}{}), `field D: invalid rule "oneof="`},
+		{"regex on int", reflect.TypeOf(struct {
+			E int `qf:"regex=^1$"`
+		}{}), "field E: regex not supported on int"},
+		{"invalid regex", reflect.TypeOf(struct {
+			F string `qf:"regex=a("`
+		}{}), `field F: invalid rule "regex=a("`},
+		{"nested", reflect.TypeOf(struct {
+			Items []struct {
+				H string `qf:"max=a"`
+			}
+		}{}), `field H: invalid rule "max=a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getValidator(tt.typ)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("getValidator() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}